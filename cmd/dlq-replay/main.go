@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	configs "wallet-service/internal/config"
+	"wallet-service/internal/transport/kafka/consumer"
+	"wallet-service/internal/transport/kafka/producer"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	cfg, err := configs.Init()
+	if err != nil {
+		logrus.Panicf("Config error: %v\n", err)
+	}
+
+	// Each replayed message names the topic it was dead-lettered from.
+	producer, err := producer.New(cfg, producer.WithTopic(""), producer.WithSync())
+	if err != nil {
+		logrus.Panicf("Producer error: %v\n", err)
+	}
+
+	defer func() {
+		if err := producer.Close(); err != nil {
+			logrus.Errorf("Close producer error: %v\n", err)
+		}
+	}()
+
+	replayer := consumer.NewReplayer(cfg, producer)
+
+	defer func() {
+		if err := replayer.Close(); err != nil {
+			logrus.Errorf("Close consumer error: %v\n", err)
+		}
+	}()
+
+	replayed, err := replayer.Replay(ctx)
+	if err != nil {
+		logrus.Panicf("Replay error: %v\n", err)
+	}
+
+	logrus.Infof("Replayed %d messages from %s", replayed, cfg.Kafka.DLQTopic)
+}
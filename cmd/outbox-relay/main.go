@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os/signal"
+	"syscall"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/lib/pq"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	configs "wallet-service/internal/config"
+	"wallet-service/internal/outbox"
+	"wallet-service/internal/repository"
+	postgresql "wallet-service/internal/repository/psql"
+	"wallet-service/internal/transport/kafka/producer"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	cfg, err := configs.Init()
+	if err != nil {
+		logrus.Panicf("Config error: %v\n", err)
+	}
+
+	psql, err := postgresql.New(cfg)
+	if err != nil {
+		logrus.Panicf("Postgres error: %v\n", err)
+	}
+
+	if err := psql.Up(); err != nil {
+		if errors.Is(err, migrate.ErrNoChange) {
+			logrus.Info("No migrations to apply.")
+		} else {
+			logrus.Panicf("Migrations error: %v\n", err)
+		}
+	}
+
+	// Wallet events are keyed by wallet id: hashing keeps each wallet's events
+	// on one partition, in order.
+	producer, err := producer.New(cfg,
+		producer.WithTopic(cfg.Outbox.Topic),
+		producer.WithBalancer(&kafka.Hash{}),
+		producer.WithSync())
+	if err != nil {
+		logrus.Panicf("Producer error: %v\n", err)
+	}
+
+	defer func() {
+		if err := producer.Close(); err != nil {
+			logrus.Errorf("Close producer error: %v\n", err)
+		}
+	}()
+
+	relay := outbox.NewRelay(cfg, repository.NewOutboxRepository(psql.Database()), producer)
+
+	if err := relay.Run(ctx); err != nil {
+		logrus.Panicf("Outbox relay error: %v\n", err)
+	}
+}
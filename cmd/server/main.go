@@ -5,11 +5,13 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
+	"wallet-service/internal/auth"
 	configs "wallet-service/internal/config"
 	"wallet-service/internal/repository"
 	postgresql "wallet-service/internal/repository/psql"
@@ -40,8 +42,14 @@ func main() {
 
 	repo := repository.NewUsersRepository(psql.Database())
 	walletRepo := repository.NewWalletRepository(psql.Database())
+	idempotencyRepo := repository.NewIdempotencyRepository(psql.Database(), cfg.HTTP.IdempotencyInFlightTimeout)
 	services := service.New(repo, walletRepo)
-	server := rest.New(services, repo)
+	authenticator, err := auth.New(cfg.Auth)
+	if err != nil {
+		logrus.Panicf("Auth error: %v\n", err)
+	}
+
+	server := rest.New(services, authenticator, idempotencyRepo, cfg.HTTP.IdempotencyTTL)
 
 	logrus.Infof("HTTP Server started on port %s\n", cfg.HTTP.Port)
 
@@ -54,6 +62,22 @@ func main() {
 		}
 	}()
 
+	go func() {
+		ticker := time.NewTicker(cfg.HTTP.IdempotencyCleanupInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			deleted, err := idempotencyRepo.DeleteExpired(ctx)
+			if err != nil {
+				logrus.Errorf("Idempotency cleanup error: %v\n", err)
+
+				continue
+			}
+
+			logrus.Infof("Deleted %d expired idempotency keys\n", deleted)
+		}
+	}()
+
 	<-quit
 
 	if err := psql.Close(); err != nil {
@@ -3,6 +3,9 @@ package main
 import (
 	"context"
 	"errors"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -13,10 +16,12 @@ import (
 	"wallet-service/internal/repository"
 	postgresql "wallet-service/internal/repository/psql"
 	"wallet-service/internal/transport/kafka/consumer"
+	"wallet-service/internal/transport/kafka/producer"
 )
 
 func main() {
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	cfg, err := configs.Init()
 	if err != nil {
@@ -28,6 +33,12 @@ func main() {
 		logrus.Panicf("Postgres error: %v\n", err)
 	}
 
+	defer func() {
+		if err := psql.Close(); err != nil {
+			logrus.Errorf("Close Postgres error: %v\n", err)
+		}
+	}()
+
 	if err := psql.Up(); err != nil {
 		if errors.Is(err, migrate.ErrNoChange) {
 			logrus.Info("No migrations to apply.")
@@ -37,10 +48,55 @@ func main() {
 	}
 
 	repo := repository.NewUsersRepository(psql.Database())
+	walletRepo := repository.NewWalletRepository(psql.Database())
+
+	dlq, err := producer.New(cfg, producer.WithTopic(cfg.Kafka.DLQTopic), producer.WithSync())
+	if err != nil {
+		logrus.Panicf("Producer error: %v\n", err)
+	}
+
+	defer func() {
+		if err := dlq.Close(); err != nil {
+			logrus.Errorf("Close producer error: %v\n", err)
+		}
+	}()
+
+	consumer := consumer.New(cfg, repo, walletRepo, dlq)
+
+	// Closing the reader leaves the consumer group right away, so the group
+	// rebalances once instead of waiting for the session to time out.
+	defer func() {
+		if err := consumer.Close(); err != nil {
+			logrus.Errorf("Close consumer error: %v\n", err)
+		}
+	}()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- consumer.Consume(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			logrus.Panicf("Consumer error: %v\n", err)
+		}
 
-	consumer := consumer.New(cfg, repo)
+		return
+	case <-ctx.Done():
+	}
+
+	logrus.Info("Shutting down the consumer...")
+
+	select {
+	case err := <-done:
+		if err != nil {
+			logrus.Errorf("Consumer error: %v\n", err)
+		}
 
-	if err := consumer.Consume(ctx); err != nil {
-		logrus.Panicf("Consumer error: %v\n", err)
+		logrus.Infof("Consumer stopped, %d stale events skipped", consumer.SkippedEvents())
+	case <-time.After(cfg.Kafka.ShutdownTimeout):
+		logrus.Errorf("Consumer did not stop within %s, uncommitted messages will be redelivered", cfg.Kafka.ShutdownTimeout)
 	}
 }
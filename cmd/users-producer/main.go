@@ -2,13 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 	configs "wallet-service/internal/config"
 	"wallet-service/internal/generator"
+	"wallet-service/internal/repository"
+	postgresql "wallet-service/internal/repository/psql"
 	"wallet-service/internal/transport/kafka/producer"
 )
 
@@ -16,33 +22,63 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	sc, err := parseScenario(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+
+	if err != nil {
+		logrus.Panicf("Scenario error: %v\n", err)
+	}
+
 	cfg, err := configs.Init()
 	if err != nil {
 		logrus.Panicf("Config error: %v\n", err)
 	}
 
-	producer := producer.New(cfg)
+	kProducer, err := producer.New(cfg)
+	if err != nil {
+		logrus.Panicf("Producer error: %v\n", err)
+	}
 
 	defer func() {
-		if err := producer.Close(); err != nil {
+		if err := kProducer.Close(); err != nil {
 			logrus.Panicf("Close producer error: %v\n", err)
 		}
 	}()
 
-	for {
-		fakeUser, err := generator.GenerateUser()
+	users := generator.NewUserGenerator(sc.Seed, sc.Mix, sc.PoolSize)
+
+	if sc.PoolFromDB {
+		psql, err := postgresql.New(cfg)
 		if err != nil {
-			logrus.Panicf("Error to create: %v\n", err)
+			logrus.Panicf("Postgres error: %v\n", err)
 		}
 
-		if err := producer.Produce(cfg, ctx, fakeUser); err != nil {
-			logrus.Panicf("Producer error: %v\n", err)
+		existing, err := repository.NewUsersRepository(psql.Database()).ListUsers(ctx, sc.PoolSize)
+		if err != nil {
+			logrus.Panicf("Postgres error: %v\n", err)
 		}
 
-		select {
-		case <-time.After(time.Second):
-		case <-ctx.Done():
-			return
+		if err := psql.Close(); err != nil {
+			logrus.Errorf("Close Postgres error: %v\n", err)
 		}
+
+		users.AddUsers(existing...)
+
+		logrus.Infof("Loaded %d existing users into the pool", len(existing))
 	}
+
+	logrus.Infof("Producing user events: rate=%g/s count=%d duration=%s workers=%d seed=%d mix=%+v",
+		sc.Rate, sc.Count, sc.Duration, sc.Workers, sc.Seed, sc.Mix)
+
+	report := generator.Run(ctx, kProducer, users, generator.Load{
+		Rate:     sc.Rate,
+		Count:    sc.Count,
+		Duration: sc.Duration.Duration,
+		Workers:  sc.Workers,
+	})
+
+	logrus.Infof("Sent %d events (%v) with %d errors in %s: %.1f events/s",
+		report.Sent, report.ByType, report.Errors, report.Elapsed.Round(time.Millisecond), report.Achieved)
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"wallet-service/internal/generator"
+)
+
+// scenario is read from the -scenario JSON file, if any, and then overridden by
+// the flags given on the command line.
+type scenario struct {
+	Rate       float64       `json:"rate"`
+	Count      int           `json:"count"`
+	Duration   duration      `json:"duration"`
+	Workers    int           `json:"workers"`
+	Seed       uint64        `json:"seed"`
+	PoolSize   int           `json:"poolSize"`
+	PoolFromDB bool          `json:"poolFromDb"`
+	Mix        generator.Mix `json:"mix"`
+}
+
+type duration struct {
+	time.Duration
+}
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string like \"1m30s\": %w", err)
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	d.Duration = parsed
+
+	return nil
+}
+
+func parseScenario(args []string) (scenario, error) {
+	sc := scenario{
+		Rate:     1,
+		Workers:  1,
+		Seed:     uint64(time.Now().UnixNano()),
+		PoolSize: 1000,
+		Mix:      generator.DefaultMix(),
+	}
+
+	flags := flag.NewFlagSet("users-producer", flag.ContinueOnError)
+
+	file := flags.String("scenario", "", "JSON scenario file; flags override its values")
+	rate := flags.Float64("rate", sc.Rate, "events per second, 0 for as fast as possible")
+	count := flags.Int("count", sc.Count, "number of events to send, 0 for no limit")
+	runFor := flags.Duration("duration", sc.Duration.Duration, "how long to run, 0 for no limit")
+	workers := flags.Int("workers", sc.Workers, "number of concurrent senders")
+	seed := flags.Uint64("seed", sc.Seed, "random seed, fix it to replay the same events")
+	poolSize := flags.Int("pool-size", sc.PoolSize, "number of existing users that receive updates")
+	poolFromDB := flags.Bool("pool-from-db", sc.PoolFromDB, "fill the user pool from the users table first")
+	create := flags.Int("mix-create", sc.Mix.Create, "relative weight of user.created events")
+	block := flags.Int("mix-block", sc.Mix.Block, "relative weight of user.blocked events")
+	unblock := flags.Int("mix-unblock", sc.Mix.Unblock, "relative weight of user.unblocked events")
+	del := flags.Int("mix-delete", sc.Mix.Delete, "relative weight of user.deleted events")
+
+	if err := flags.Parse(args); err != nil {
+		return scenario{}, err
+	}
+
+	if *file != "" {
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			return scenario{}, fmt.Errorf("failed to read the scenario: %w", err)
+		}
+
+		if err := json.Unmarshal(data, &sc); err != nil {
+			return scenario{}, fmt.Errorf("failed to parse the scenario: %w", err)
+		}
+
+		// A mix in the file replaces the default one as a whole: event types it
+		// leaves out are not generated.
+		var fileMix struct {
+			Mix *generator.Mix `json:"mix"`
+		}
+
+		if err := json.Unmarshal(data, &fileMix); err != nil {
+			return scenario{}, fmt.Errorf("failed to parse the scenario: %w", err)
+		}
+
+		if fileMix.Mix != nil {
+			sc.Mix = *fileMix.Mix
+		}
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "rate":
+			sc.Rate = *rate
+		case "count":
+			sc.Count = *count
+		case "duration":
+			sc.Duration.Duration = *runFor
+		case "workers":
+			sc.Workers = *workers
+		case "seed":
+			sc.Seed = *seed
+		case "pool-size":
+			sc.PoolSize = *poolSize
+		case "pool-from-db":
+			sc.PoolFromDB = *poolFromDB
+		case "mix-create":
+			sc.Mix.Create = *create
+		case "mix-block":
+			sc.Mix.Block = *block
+		case "mix-unblock":
+			sc.Mix.Unblock = *unblock
+		case "mix-delete":
+			sc.Mix.Delete = *del
+		}
+	})
+
+	return sc, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"wallet-service/internal/generator"
+)
+
+func TestParseScenarioMix(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		args []string
+		want generator.Mix
+	}{
+		{name: "no file", want: generator.DefaultMix()},
+		{name: "file without mix", file: `{"rate": 10}`, want: generator.DefaultMix()},
+		{name: "partial mix replaces the default", file: `{"mix": {"create": 1, "block": 2}}`,
+			want: generator.Mix{Create: 1, Block: 2}},
+		{name: "flags override the file", file: `{"mix": {"create": 1}}`, args: []string{"-mix-delete", "3"},
+			want: generator.Mix{Create: 1, Delete: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+
+			if tt.file != "" {
+				path := filepath.Join(t.TempDir(), "scenario.json")
+				require.NoError(t, os.WriteFile(path, []byte(tt.file), 0o600))
+
+				args = append([]string{"-scenario", path}, args...)
+			}
+
+			sc, err := parseScenario(args)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, sc.Mix)
+		})
+	}
+}
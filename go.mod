@@ -3,9 +3,12 @@ module wallet-service
 go 1.24.3
 
 require (
+	github.com/golang-jwt/jwt/v5 v5.3.1
 	github.com/lib/pq v1.10.9
 	github.com/segmentio/kafka-go v0.4.48
 	github.com/stretchr/testify v1.10.0
+	github.com/swaggo/files/v2 v2.0.2
+	google.golang.org/protobuf v1.36.6
 )
 
 require (
@@ -20,7 +23,6 @@ require (
 )
 
 require (
-	github.com/brianvoe/gofakeit v3.18.0+incompatible
 	github.com/golang-migrate/migrate/v4 v4.18.3
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/mux v1.8.1
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	configs "wallet-service/internal/config"
+)
+
+var (
+	ErrNoKeys       = errors.New("neither an HMAC secret nor a JWKS file is configured")
+	ErrUnknownKey   = errors.New("no key to verify the token")
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Authenticator verifies HS256 tokens signed with the shared secret and RS256
+// tokens signed with a key from the JWKS file; the subject is the user ID.
+type Authenticator struct {
+	hmacSecret []byte
+	rsaKeys    map[string]*rsa.PublicKey
+	parser     *jwt.Parser
+}
+
+func New(cfg configs.AuthConfig) (*Authenticator, error) {
+	authenticator := &Authenticator{
+		hmacSecret: []byte(cfg.HMACSecret),
+	}
+
+	var methods []string
+
+	if cfg.HMACSecret != "" {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+
+		authenticator.rsaKeys = keys
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	if len(methods) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	authenticator.parser = jwt.NewParser(options...)
+
+	return authenticator, nil
+}
+
+func (a *Authenticator) Authenticate(tokenString string) (uuid.UUID, error) {
+	var claims jwt.RegisteredClaims
+
+	if _, err := a.parser.ParseWithClaims(tokenString, &claims, a.key); err != nil {
+		return uuid.UUID{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	userId, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("%w: subject is not a user ID: %w", ErrInvalidToken, err)
+	}
+
+	return userId, nil
+}
+
+func (a *Authenticator) key(token *jwt.Token) (any, error) {
+	if token.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+		return a.hmacSecret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	if key, ok := a.rsaKeys[kid]; ok {
+		return key, nil
+	}
+
+	// A token without a kid is accepted only when the choice is unambiguous.
+	if kid == "" && len(a.rsaKeys) == 1 {
+		for _, key := range a.rsaKeys {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: kid %q", ErrUnknownKey, kid)
+}
@@ -0,0 +1,349 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	configs "wallet-service/internal/config"
+)
+
+// rsaKeys are generated once, as generating RSA keys is slow.
+var rsaKeys = sync.OnceValue(func() []*rsa.PrivateKey {
+	keys := make([]*rsa.PrivateKey, 2)
+
+	for i := range keys {
+		key, err := rsa.GenerateKey(rand.Reader, minRSAKeyBits)
+		if err != nil {
+			panic(err)
+		}
+
+		keys[i] = key
+	}
+
+	return keys
+})
+
+func webKey(kid string, key *rsa.PublicKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func writeJWKS(t *testing.T, keys ...jsonWebKey) string {
+	t.Helper()
+
+	data, err := json.Marshal(jsonWebKeySet{Keys: keys})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func validClaims(subject uuid.UUID) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   subject.String(),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func TestLoadJWKS(t *testing.T) {
+	public := &rsaKeys()[0].PublicKey
+
+	shortKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	withExponent := func(e []byte) jsonWebKey {
+		jwk := webKey("a", public)
+		jwk.E = base64.RawURLEncoding.EncodeToString(e)
+
+		return jwk
+	}
+
+	encryption := webKey("enc", public)
+	encryption.Use = "enc"
+
+	otherAlg := webKey("ps", public)
+	otherAlg.Alg = "PS256"
+
+	ec := jsonWebKey{Kty: "EC", Kid: "ec"}
+
+	tests := []struct {
+		name     string
+		keys     []jsonWebKey
+		wantKids []string
+		wantErr  bool
+	}{
+		{name: "signing keys", keys: []jsonWebKey{webKey("a", public), webKey("b", &rsaKeys()[1].PublicKey)},
+			wantKids: []string{"a", "b"}},
+		{name: "other keys are skipped", keys: []jsonWebKey{ec, encryption, otherAlg, webKey("a", public)},
+			wantKids: []string{"a"}},
+		{name: "no signing keys", keys: []jsonWebKey{ec, encryption, otherAlg}, wantErr: true},
+		{name: "empty set", keys: nil, wantErr: true},
+		{name: "malformed modulus", keys: []jsonWebKey{{Kty: "RSA", Kid: "a", N: "!!", E: "AQAB"}}, wantErr: true},
+		{name: "modulus too short", keys: []jsonWebKey{webKey("a", &shortKey.PublicKey)}, wantErr: true},
+		{name: "exponent too large", keys: []jsonWebKey{withExponent([]byte{1, 0, 0, 0, 1})}, wantErr: true},
+		{name: "exponent of one", keys: []jsonWebKey{withExponent([]byte{1})}, wantErr: true},
+		{name: "even exponent", keys: []jsonWebKey{withExponent([]byte{1, 0, 0})}, wantErr: true},
+		{name: "largest exponent", keys: []jsonWebKey{withExponent([]byte{0x7f, 0xff, 0xff, 0xff})}, wantKids: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := loadJWKS(writeJWKS(t, tt.keys...))
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidJWKS)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			kids := make([]string, 0, len(keys))
+			for kid := range keys {
+				kids = append(kids, kid)
+			}
+
+			require.ElementsMatch(t, tt.wantKids, kids)
+		})
+	}
+
+	t.Run("not JSON", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "jwks.json")
+		require.NoError(t, os.WriteFile(path, []byte("keys"), 0o600))
+
+		_, err := loadJWKS(path)
+		require.ErrorIs(t, err, ErrInvalidJWKS)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := loadJWKS(filepath.Join(t.TempDir(), "missing.json"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestNewRequiresKeys(t *testing.T) {
+	_, err := New(configs.AuthConfig{})
+	require.ErrorIs(t, err, ErrNoKeys)
+}
+
+func TestAuthenticateKeySelection(t *testing.T) {
+	first, second := rsaKeys()[0], rsaKeys()[1]
+	userId := uuid.New()
+
+	twoKeys, err := New(configs.AuthConfig{JWKSFile: writeJWKS(t, webKey("a", &first.PublicKey), webKey("b", &second.PublicKey))})
+	require.NoError(t, err)
+
+	oneKey, err := New(configs.AuthConfig{JWKSFile: writeJWKS(t, webKey("a", &first.PublicKey))})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		authenticator *Authenticator
+		key           *rsa.PrivateKey
+		kid           string
+		wantErr       error
+	}{
+		{name: "first key", authenticator: twoKeys, key: first, kid: "a"},
+		{name: "second key", authenticator: twoKeys, key: second, kid: "b"},
+		{name: "kid of another key", authenticator: twoKeys, key: first, kid: "b", wantErr: ErrInvalidToken},
+		{name: "unknown kid", authenticator: twoKeys, key: first, kid: "c", wantErr: ErrUnknownKey},
+		{name: "no kid with several keys", authenticator: twoKeys, key: first, wantErr: ErrUnknownKey},
+		{name: "no kid with a single key", authenticator: oneKey, key: first},
+		{name: "unknown kid with a single key", authenticator: oneKey, key: first, kid: "c", wantErr: ErrUnknownKey},
+		{name: "key not in the set", authenticator: oneKey, key: second, wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := sign(t, jwt.SigningMethodRS256, tt.key, tt.kid, validClaims(userId))
+
+			got, err := tt.authenticator.Authenticate(token)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.ErrorIs(t, err, ErrInvalidToken)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, userId, got)
+		})
+	}
+}
+
+func TestAuthenticateAlgorithmConfusion(t *testing.T) {
+	public := &rsaKeys()[0].PublicKey
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	jwks := writeJWKS(t, webKey("a", public))
+
+	tests := []struct {
+		name string
+		cfg  configs.AuthConfig
+	}{
+		{name: "JWKS only", cfg: configs.AuthConfig{JWKSFile: jwks}},
+		{name: "JWKS and HMAC secret", cfg: configs.AuthConfig{JWKSFile: jwks, HMACSecret: "secret"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator, err := New(tt.cfg)
+			require.NoError(t, err)
+
+			claims := validClaims(uuid.New())
+
+			// An attacker who knows the public key signs an HS256 token with it.
+			for _, secret := range [][]byte{publicPEM, der} {
+				token := sign(t, jwt.SigningMethodHS256, secret, "a", claims)
+
+				_, err := authenticator.Authenticate(token)
+				require.ErrorIs(t, err, ErrInvalidToken)
+			}
+
+			unsigned := sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "a", claims)
+
+			_, err = authenticator.Authenticate(unsigned)
+			require.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+
+	t.Run("RS256 token with HMAC only", func(t *testing.T) {
+		authenticator, err := New(configs.AuthConfig{HMACSecret: "secret"})
+		require.NoError(t, err)
+
+		token := sign(t, jwt.SigningMethodRS256, rsaKeys()[0], "a", validClaims(uuid.New()))
+
+		_, err = authenticator.Authenticate(token)
+		require.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+	})
+}
+
+func TestAuthenticateClaims(t *testing.T) {
+	key := rsaKeys()[0]
+	userId := uuid.New()
+	now := time.Now()
+
+	authenticator, err := New(configs.AuthConfig{
+		HMACSecret: "secret",
+		JWKSFile:   writeJWKS(t, webKey("a", &key.PublicKey)),
+		Issuer:     "https://auth.example.com",
+		Audience:   "wallet-service",
+		Leeway:     30 * time.Second,
+	})
+	require.NoError(t, err)
+
+	claims := func(change func(*jwt.RegisteredClaims)) jwt.RegisteredClaims {
+		claims := validClaims(userId)
+		claims.Issuer = "https://auth.example.com"
+		claims.Audience = jwt.ClaimStrings{"wallet-service"}
+
+		if change != nil {
+			change(&claims)
+		}
+
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		claims  jwt.RegisteredClaims
+		wantErr error
+	}{
+		{name: "valid", claims: claims(nil)},
+		{name: "one of several audiences", claims: claims(func(c *jwt.RegisteredClaims) {
+			c.Audience = jwt.ClaimStrings{"other", "wallet-service"}
+		})},
+		{name: "expired", claims: claims(func(c *jwt.RegisteredClaims) {
+			c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute))
+		}), wantErr: jwt.ErrTokenExpired},
+		{name: "expired within the leeway", claims: claims(func(c *jwt.RegisteredClaims) {
+			c.ExpiresAt = jwt.NewNumericDate(now.Add(-10 * time.Second))
+		})},
+		{name: "without expiry", claims: claims(func(c *jwt.RegisteredClaims) {
+			c.ExpiresAt = nil
+		}), wantErr: jwt.ErrTokenRequiredClaimMissing},
+		{name: "not yet valid", claims: claims(func(c *jwt.RegisteredClaims) {
+			c.NotBefore = jwt.NewNumericDate(now.Add(time.Minute))
+		}), wantErr: jwt.ErrTokenNotValidYet},
+		{name: "not yet valid within the leeway", claims: claims(func(c *jwt.RegisteredClaims) {
+			c.NotBefore = jwt.NewNumericDate(now.Add(10 * time.Second))
+		})},
+		{name: "other issuer", claims: claims(func(c *jwt.RegisteredClaims) {
+			c.Issuer = "https://evil.example.com"
+		}), wantErr: jwt.ErrTokenInvalidIssuer},
+		{name: "no issuer", claims: claims(func(c *jwt.RegisteredClaims) {
+			c.Issuer = ""
+		}), wantErr: jwt.ErrTokenRequiredClaimMissing},
+		{name: "other audience", claims: claims(func(c *jwt.RegisteredClaims) {
+			c.Audience = jwt.ClaimStrings{"other"}
+		}), wantErr: jwt.ErrTokenInvalidAudience},
+		{name: "no audience", claims: claims(func(c *jwt.RegisteredClaims) {
+			c.Audience = nil
+		}), wantErr: jwt.ErrTokenRequiredClaimMissing},
+		{name: "subject is not a user ID", claims: claims(func(c *jwt.RegisteredClaims) {
+			c.Subject = "admin"
+		}), wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		for _, method := range []string{"HS256", "RS256"} {
+			t.Run(tt.name+" "+method, func(t *testing.T) {
+				var token string
+
+				if method == "HS256" {
+					token = sign(t, jwt.SigningMethodHS256, []byte("secret"), "", tt.claims)
+				} else {
+					token = sign(t, jwt.SigningMethodRS256, key, "a", tt.claims)
+				}
+
+				got, err := authenticator.Authenticate(token)
+				if tt.wantErr != nil {
+					require.ErrorIs(t, err, tt.wantErr)
+					require.ErrorIs(t, err, ErrInvalidToken)
+
+					return
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, userId, got)
+			})
+		}
+	}
+}
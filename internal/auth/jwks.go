@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+var ErrInvalidJWKS = errors.New("invalid JWKS")
+
+// minRSAKeyBits is the smallest RSA modulus accepted, as RFC 7518 requires for
+// RS256.
+const minRSAKeyBits = 2048
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// loadJWKS reads the RSA signing keys of a JSON Web Key Set (RFC 7517) file,
+// indexed by key ID. Keys of other types or for other uses are skipped.
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	var set jsonWebKeySet
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJWKS, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") || (jwk.Alg != "" && jwk.Alg != "RS256") {
+			continue
+		}
+
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %w", ErrInvalidJWKS, jwk.Kid, err)
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no RS256 signing keys", ErrInvalidJWKS)
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode modulus: %w", err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode exponent: %w", err)
+	}
+
+	modulus := new(big.Int).SetBytes(n)
+	if modulus.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("modulus of %d bits is shorter than %d", modulus.BitLen(), minRSAKeyBits)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("exponent is too large")
+	}
+
+	if exponent.Int64() < 3 || exponent.Bit(0) == 0 {
+		return nil, fmt.Errorf("exponent %d is not an odd number of at least 3", exponent.Int64())
+	}
+
+	return &rsa.PublicKey{
+		N: modulus,
+		E: int(exponent.Int64()),
+	}, nil
+}
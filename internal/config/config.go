@@ -13,12 +13,21 @@ type (
 		HTTP     HTTPConfig
 		Postgres PostgreSQLConfig
 		Kafka    KafkaConfig
+		Auth     AuthConfig
+		Outbox   OutboxConfig
 	}
 
 	HTTPConfig struct {
 		Port         string        `envconfig:"HTTP_PORT" default:"8080"`
 		ReadTimeout  time.Duration `envconfig:"HTTP_READ_TIMEOUT" default:"10s"`
 		WriteTimeout time.Duration `envconfig:"HTTP_WRITE_TIMEOUT" default:"10s"`
+
+		IdempotencyTTL             time.Duration `envconfig:"HTTP_IDEMPOTENCY_TTL" default:"24h"`
+		IdempotencyCleanupInterval time.Duration `envconfig:"HTTP_IDEMPOTENCY_CLEANUP_INTERVAL" default:"1h"`
+		// IdempotencyInFlightTimeout is how long a key stays claimed by a request
+		// that never finished, e.g. because the server crashed, before a retry
+		// may claim it again.
+		IdempotencyInFlightTimeout time.Duration `envconfig:"HTTP_IDEMPOTENCY_IN_FLIGHT_TIMEOUT" default:"1m"`
 	}
 
 	PostgreSQLConfig struct {
@@ -34,9 +43,73 @@ type (
 		Brokers []string `envconfig:"KAFKA_BROKERS" default:"localhost:9094"`
 		GroupID string   `envconfig:"KAFKA_GROUP_ID" default:"wallet_users"`
 		Topic   string   `envconfig:"KAFKA_TOPIC" default:"users"`
+
+		// ContentType selects the codec of produced messages: application/json
+		// or application/x-protobuf. Consumers follow each message's header.
+		ContentType string `envconfig:"KAFKA_CONTENT_TYPE" default:"application/json"`
+
+		CommitBatchSize int           `envconfig:"KAFKA_COMMIT_BATCH_SIZE" default:"100"`
+		CommitInterval  time.Duration `envconfig:"KAFKA_COMMIT_INTERVAL" default:"1s"`
+
+		// A message failing for a transient reason is tried RetryMaxAttempts
+		// times before the consumer stops without committing it. Messages that
+		// can never succeed go to the dead-letter topic without retries.
+		RetryMaxAttempts    int           `envconfig:"KAFKA_RETRY_MAX_ATTEMPTS" default:"5"`
+		RetryInitialBackoff time.Duration `envconfig:"KAFKA_RETRY_INITIAL_BACKOFF" default:"100ms"`
+		RetryMaxBackoff     time.Duration `envconfig:"KAFKA_RETRY_MAX_BACKOFF" default:"10s"`
+
+		DLQTopic             string        `envconfig:"KAFKA_DLQ_TOPIC" default:"users-dlq"`
+		DLQReplayGroupID     string        `envconfig:"KAFKA_DLQ_REPLAY_GROUP_ID" default:"wallet_users_dlq_replay"`
+		DLQReplayIdleTimeout time.Duration `envconfig:"KAFKA_DLQ_REPLAY_IDLE_TIMEOUT" default:"5s"`
+
+		ShutdownTimeout time.Duration `envconfig:"KAFKA_SHUTDOWN_TIMEOUT" default:"20s"`
+
+		// Concurrency is the number of messages handled at once. Ordering keeps
+		// messages of the same partition, or with the same key, in order.
+		Concurrency int    `envconfig:"KAFKA_CONCURRENCY" default:"4"`
+		Ordering    string `envconfig:"KAFKA_ORDERING" default:"partition"`
+
+		// BatchSize above one makes each worker write up to BatchSize users in
+		// one statement, waiting at most BatchTimeout to fill a batch.
+		BatchSize    int           `envconfig:"KAFKA_BATCH_SIZE" default:"1"`
+		BatchTimeout time.Duration `envconfig:"KAFKA_BATCH_TIMEOUT" default:"100ms"`
+
+		// Producer settings: balancer is hash, round-robin or least-bytes;
+		// compression is none, gzip, snappy, lz4 or zstd; required acks is
+		// all, one or none.
+		ProducerBalancer     string        `envconfig:"KAFKA_PRODUCER_BALANCER" default:"hash"`
+		ProducerCompression  string        `envconfig:"KAFKA_PRODUCER_COMPRESSION" default:"none"`
+		ProducerRequiredAcks string        `envconfig:"KAFKA_PRODUCER_REQUIRED_ACKS" default:"all"`
+		ProducerAsync        bool          `envconfig:"KAFKA_PRODUCER_ASYNC" default:"false"`
+		ProducerBatchSize    int           `envconfig:"KAFKA_PRODUCER_BATCH_SIZE" default:"100"`
+		ProducerBatchTimeout time.Duration `envconfig:"KAFKA_PRODUCER_BATCH_TIMEOUT" default:"10ms"`
+	}
+
+	OutboxConfig struct {
+		Topic        string        `envconfig:"OUTBOX_TOPIC" default:"wallet-events"`
+		PollInterval time.Duration `envconfig:"OUTBOX_POLL_INTERVAL" default:"1s"`
+		BatchSize    int           `envconfig:"OUTBOX_BATCH_SIZE" default:"100"`
+		ContentType  string        `envconfig:"OUTBOX_CONTENT_TYPE" default:"application/json"`
+		// Retention is how long sent messages are kept, e.g. for debugging,
+		// before the relay purges them every PurgeInterval. Zero keeps them.
+		Retention     time.Duration `envconfig:"OUTBOX_RETENTION" default:"168h"`
+		PurgeInterval time.Duration `envconfig:"OUTBOX_PURGE_INTERVAL" default:"1h"`
+	}
+
+	AuthConfig struct {
+		HMACSecret string        `envconfig:"AUTH_HMAC_SECRET"`
+		JWKSFile   string        `envconfig:"AUTH_JWKS_FILE"`
+		Issuer     string        `envconfig:"AUTH_ISSUER"`
+		Audience   string        `envconfig:"AUTH_AUDIENCE"`
+		Leeway     time.Duration `envconfig:"AUTH_LEEWAY" default:"30s"`
 	}
 )
 
+const (
+	OrderingPartition = "partition"
+	OrderingKey       = "key"
+)
+
 func Init() (*Config, error) {
 	var cfg Config
 
@@ -44,6 +117,11 @@ func Init() (*Config, error) {
 		return nil, fmt.Errorf("failed to process all configs: %w", err)
 	}
 
+	if cfg.Kafka.Ordering != OrderingPartition && cfg.Kafka.Ordering != OrderingKey {
+		return nil, fmt.Errorf("invalid KAFKA_ORDERING %q: must be %q or %q",
+			cfg.Kafka.Ordering, OrderingPartition, OrderingKey)
+	}
+
 	return &cfg, nil
 }
 
@@ -0,0 +1,64 @@
+package domain
+
+import "strings"
+
+type ErrorKind int
+
+const (
+	KindNotFound ErrorKind = iota + 1
+	KindForbidden
+	KindConflict
+	KindValidation
+	KindInsufficientFunds
+	KindPreconditionFailed
+	KindUnprocessable
+)
+
+// Error is a failure the client can act on. Kind decides how it is reported
+// (e.g. the HTTP status) and Code is a stable machine-readable identifier.
+// Fields lists the offending request fields of a validation error.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Fields  []FieldError
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func NewError(kind ErrorKind, code, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// NewFieldsError reports every invalid field of a request at once, so that the
+// client can fix them all before retrying.
+func NewFieldsError(fields []FieldError) *Error {
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field.Field+" "+field.Message)
+	}
+
+	return &Error{
+		Kind:    KindValidation,
+		Code:    "invalid_fields",
+		Message: "invalid fields: " + strings.Join(messages, "; "),
+		Fields:  fields,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+var (
+	ErrUserNotFound = NewError(KindNotFound, "user_not_found", "user not found")
+	ErrUserDeleted  = NewError(KindNotFound, "user_deleted", "user is deleted")
+	ErrUserBlocked  = NewError(KindForbidden, "user_blocked", "user is blocked")
+)
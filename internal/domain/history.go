@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = NewError(KindValidation, "invalid_cursor", "invalid pagination cursor")
+	ErrInvalidFilter = NewError(KindValidation, "invalid_filter", "invalid filter")
+	ErrInvalidLimit  = NewError(KindValidation, "invalid_limit", "limit must be a positive integer")
+)
+
+type WalletTransactionType string
+
+const (
+	WalletTransactionDeposit     WalletTransactionType = "deposit"
+	WalletTransactionWithdrawal  WalletTransactionType = "withdrawal"
+	WalletTransactionTransferIn  WalletTransactionType = "transfer-in"
+	WalletTransactionTransferOut WalletTransactionType = "transfer-out"
+)
+
+func (t WalletTransactionType) Valid() bool {
+	switch t {
+	case WalletTransactionDeposit, WalletTransactionWithdrawal, WalletTransactionTransferIn, WalletTransactionTransferOut:
+		return true
+	default:
+		return false
+	}
+}
+
+// WalletTransaction is a money movement as seen from one wallet.
+type WalletTransaction struct {
+	Id                   uuid.UUID             `json:"id"`
+	Type                 WalletTransactionType `json:"type"`
+	Amount               Money                 `json:"amount"`
+	CounterpartyWalletId *uuid.UUID            `json:"counterpartyWalletId,omitempty"`
+	CreatedAt            time.Time             `json:"createdAt"`
+}
+
+type TransactionFilter struct {
+	Limit  int
+	Cursor string
+	From   *time.Time
+	To     *time.Time
+	Types  []WalletTransactionType
+}
+
+type TransactionPage struct {
+	Transactions []WalletTransaction `json:"transactions"`
+	NextCursor   string              `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,16 @@
+package domain
+
+var (
+	ErrIdempotencyKeyReused = NewError(KindUnprocessable, "idempotency_key_reused",
+		"idempotency key was already used for a different request")
+	ErrIdempotencyKeyInFlight = NewError(KindConflict, "idempotency_key_in_flight",
+		"a request with this idempotency key is still in progress")
+)
+
+// IdempotentResponse is the stored outcome of the first request made with an
+// idempotency key, replayed verbatim for every retry.
+type IdempotentResponse struct {
+	StatusCode int
+	Headers    map[string][]string
+	Body       []byte
+}
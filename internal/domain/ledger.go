@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrUnbalancedTransaction = NewError(KindValidation, "unbalanced_transaction", "transaction debits and credits do not balance")
+
+type TransactionType string
+
+const (
+	TransactionDeposit    TransactionType = "deposit"
+	TransactionWithdrawal TransactionType = "withdrawal"
+	TransactionTransfer   TransactionType = "transfer"
+)
+
+type EntryDirection string
+
+const (
+	Debit  EntryDirection = "debit"
+	Credit EntryDirection = "credit"
+)
+
+// Transaction is a journal record: a set of ledger entries whose debits and
+// credits balance per currency. Money entering or leaving the service is
+// posted against the external account, which is an entry without a wallet.
+type Transaction struct {
+	Id        uuid.UUID       `json:"id"        db:"id"`
+	Type      TransactionType `json:"type"      db:"type"`
+	CreatedAt time.Time       `json:"createdAt" db:"created_at"`
+	Entries   []LedgerEntry   `json:"entries"   db:"-"`
+}
+
+type LedgerEntry struct {
+	Id            int64          `json:"id"            db:"id"`
+	TransactionId uuid.UUID      `json:"transactionId" db:"transaction_id"`
+	WalletId      *uuid.UUID     `json:"walletId"      db:"wallet_id"`
+	Direction     EntryDirection `json:"direction"     db:"direction"`
+	Amount        Money          `json:"amount"        db:"amount"`
+	CreatedAt     time.Time      `json:"createdAt"     db:"created_at"`
+}
+
+// Delta is the effect of the entry on the wallet balance: wallets are
+// liabilities of the service, so credits increase them and debits decrease them.
+func (e LedgerEntry) Delta() int64 {
+	if e.Direction == Debit {
+		return -e.Amount.Amount
+	}
+
+	return e.Amount.Amount
+}
+
+func (t Transaction) Validate() error {
+	if len(t.Entries) < 2 {
+		return ErrUnbalancedTransaction
+	}
+
+	sums := make(map[string]int64)
+
+	for _, entry := range t.Entries {
+		if !entry.Amount.IsPositive() || (entry.Direction != Debit && entry.Direction != Credit) {
+			return ErrUnbalancedTransaction
+		}
+
+		sums[entry.Amount.Currency] += entry.Delta()
+	}
+
+	for _, sum := range sums {
+		if sum != 0 {
+			return ErrUnbalancedTransaction
+		}
+	}
+
+	return nil
+}
+
+func NewDepositTransaction(walletId uuid.UUID, amount Money) Transaction {
+	return newTransaction(TransactionDeposit, nil, &walletId, amount)
+}
+
+func NewWithdrawalTransaction(walletId uuid.UUID, amount Money) Transaction {
+	return newTransaction(TransactionWithdrawal, &walletId, nil, amount)
+}
+
+func NewTransferTransaction(fromWalletId, toWalletId uuid.UUID, amount Money) Transaction {
+	return newTransaction(TransactionTransfer, &fromWalletId, &toWalletId, amount)
+}
+
+func newTransaction(txType TransactionType, debitWalletId, creditWalletId *uuid.UUID, amount Money) Transaction {
+	transactionId := uuid.New()
+
+	return Transaction{
+		Id:   transactionId,
+		Type: txType,
+		Entries: []LedgerEntry{
+			{
+				TransactionId: transactionId,
+				WalletId:      debitWalletId,
+				Direction:     Debit,
+				Amount:        amount,
+			},
+			{
+				TransactionId: transactionId,
+				WalletId:      creditWalletId,
+				Direction:     Credit,
+				Amount:        amount,
+			},
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionValidate(t *testing.T) {
+	walletId := uuid.New()
+	otherId := uuid.New()
+	usd := NewMoney(1000, "USD")
+
+	entry := func(walletId *uuid.UUID, direction EntryDirection, amount Money) LedgerEntry {
+		return LedgerEntry{WalletId: walletId, Direction: direction, Amount: amount}
+	}
+
+	tests := []struct {
+		name    string
+		entries []LedgerEntry
+		wantErr bool
+	}{
+		{name: "deposit", entries: NewDepositTransaction(walletId, usd).Entries},
+		{name: "withdrawal", entries: NewWithdrawalTransaction(walletId, usd).Entries},
+		{name: "transfer", entries: NewTransferTransaction(walletId, otherId, usd).Entries},
+		{name: "split credit", entries: []LedgerEntry{
+			entry(&walletId, Debit, usd),
+			entry(&otherId, Credit, NewMoney(600, "USD")),
+			entry(nil, Credit, NewMoney(400, "USD")),
+		}},
+		{name: "balanced per currency", entries: []LedgerEntry{
+			entry(&walletId, Debit, usd),
+			entry(nil, Credit, usd),
+			entry(nil, Debit, NewMoney(500, "EUR")),
+			entry(&otherId, Credit, NewMoney(500, "EUR")),
+		}},
+		{name: "single entry", entries: []LedgerEntry{entry(&walletId, Credit, usd)}, wantErr: true},
+		{name: "amounts differ", entries: []LedgerEntry{
+			entry(&walletId, Debit, usd),
+			entry(&otherId, Credit, NewMoney(999, "USD")),
+		}, wantErr: true},
+		{name: "currencies differ", entries: []LedgerEntry{
+			entry(&walletId, Debit, usd),
+			entry(&otherId, Credit, NewMoney(1000, "EUR")),
+		}, wantErr: true},
+		{name: "zero amount", entries: []LedgerEntry{
+			entry(&walletId, Debit, NewMoney(0, "USD")),
+			entry(&otherId, Credit, NewMoney(0, "USD")),
+		}, wantErr: true},
+		{name: "negative amount", entries: []LedgerEntry{
+			entry(&walletId, Debit, NewMoney(-1000, "USD")),
+			entry(&otherId, Debit, usd),
+		}, wantErr: true},
+		{name: "unknown direction", entries: []LedgerEntry{
+			entry(&walletId, "refund", usd),
+			entry(&otherId, Credit, usd),
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Transaction{Id: uuid.New(), Type: TransactionTransfer, Entries: tt.entries}.Validate()
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrUnbalancedTransaction)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLedgerEntryDelta(t *testing.T) {
+	transaction := NewTransferTransaction(uuid.New(), uuid.New(), NewMoney(250, "USD"))
+
+	require.Equal(t, int64(-250), transaction.Entries[0].Delta(), "the debited wallet loses the amount")
+	require.Equal(t, int64(250), transaction.Entries[1].Delta(), "the credited wallet gains the amount")
+}
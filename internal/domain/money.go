@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidMoney = NewError(KindValidation, "invalid_money", "invalid money amount")
+
+// Money is an exact amount in the minor units of an ISO 4217 currency,
+// e.g. {1050, "USD"} is 10.50 USD.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{
+		Amount:   amount,
+		Currency: currency,
+	}
+}
+
+// ParseMoney parses a decimal string such as "10.50" into minor units of the
+// currency. Amounts with more fractional digits than the currency allows are
+// rejected rather than rounded.
+func ParseMoney(value, currency string) (Money, error) {
+	exponent, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", err, currency)
+	}
+
+	digits, negative := strings.CutPrefix(value, "-")
+	intPart, fracPart, hasDot := strings.Cut(digits, ".")
+
+	if intPart == "" || (hasDot && fracPart == "") || len(fracPart) > exponent ||
+		!isDigits(intPart) || !isDigits(fracPart) {
+		return Money{}, fmt.Errorf("%w: %q for %s", ErrInvalidMoney, value, currency)
+	}
+
+	fracPart += strings.Repeat("0", exponent-len(fracPart))
+
+	amount, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q: %w", ErrInvalidMoney, value, err)
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return NewMoney(amount, currency), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String renders the amount as a decimal string without the currency.
+func (m Money) String() string {
+	exponent, err := CurrencyExponent(m.Currency)
+	if err != nil {
+		return strconv.FormatInt(m.Amount, 10)
+	}
+
+	sign := ""
+	abs := uint64(m.Amount)
+
+	if m.Amount < 0 {
+		sign = "-"
+		abs = uint64(-(m.Amount + 1)) + 1
+	}
+
+	if exponent == 0 {
+		return sign + strconv.FormatUint(abs, 10)
+	}
+
+	scale := uint64(math.Pow10(exponent))
+
+	return fmt.Sprintf("%s%d.%0*d", sign, abs/scale, exponent, abs%scale)
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	amount, err := json.Marshal(m.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal money amount: %w", err)
+	}
+
+	return json.Marshal(moneyJSON{
+		Amount:   amount,
+		Currency: m.Currency,
+	})
+}
+
+// UnmarshalJSON accepts the amount both as a decimal string and as a bare JSON
+// number; in both cases the literal text is parsed, never a float64. A null
+// leaves the Money unchanged, like an omitted member, and unknown members are
+// rejected as they are for the request body around it.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var raw moneyJSON
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&raw); err != nil {
+		return fmt.Errorf("failed to unmarshal money: %w", err)
+	}
+
+	amount := string(raw.Amount)
+
+	if strings.HasPrefix(amount, `"`) {
+		if err := json.Unmarshal(raw.Amount, &amount); err != nil {
+			return fmt.Errorf("failed to unmarshal money amount: %w", err)
+		}
+	}
+
+	money, err := ParseMoney(amount, raw.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = money
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		currency string
+		want     int64
+		wantErr  error
+	}{
+		{name: "no minor unit", value: "1500", currency: "JPY", want: 1500},
+		{name: "no minor unit with a fraction", value: "1500.5", currency: "JPY", wantErr: ErrInvalidMoney},
+		{name: "no minor unit with a trailing dot", value: "1500.", currency: "JPY", wantErr: ErrInvalidMoney},
+		{name: "two decimals", value: "10.50", currency: "USD", want: 1050},
+		{name: "two decimals, one given", value: "10.5", currency: "USD", want: 1050},
+		{name: "two decimals, none given", value: "10", currency: "USD", want: 1000},
+		{name: "two decimals, three given", value: "10.505", currency: "USD", wantErr: ErrInvalidMoney},
+		{name: "three decimals", value: "1.234", currency: "KWD", want: 1234},
+		{name: "three decimals, four given", value: "1.2345", currency: "KWD", wantErr: ErrInvalidMoney},
+		{name: "four decimals", value: "0.0001", currency: "CLF", want: 1},
+		{name: "negative", value: "-10.50", currency: "USD", want: -1050},
+		{name: "negative zero", value: "-0", currency: "USD", want: 0},
+		{name: "negative zero with decimals", value: "-0.00", currency: "USD", want: 0},
+		{name: "zero", value: "0.00", currency: "USD", want: 0},
+		{name: "leading zeros", value: "007.01", currency: "USD", want: 701},
+		{name: "largest amount", value: "92233720368547758.07", currency: "USD", want: math.MaxInt64},
+		{name: "above the largest amount", value: "92233720368547758.08", currency: "USD", wantErr: ErrInvalidMoney},
+		{name: "far above the largest amount", value: "1000000000000000000000", currency: "JPY", wantErr: ErrInvalidMoney},
+		{name: "most negative but one", value: "-92233720368547758.07", currency: "USD", want: -math.MaxInt64},
+		{name: "empty", value: "", currency: "USD", wantErr: ErrInvalidMoney},
+		{name: "missing integer part", value: ".50", currency: "USD", wantErr: ErrInvalidMoney},
+		{name: "plus sign", value: "+1.00", currency: "USD", wantErr: ErrInvalidMoney},
+		{name: "double minus", value: "--1", currency: "USD", wantErr: ErrInvalidMoney},
+		{name: "exponent", value: "1e3", currency: "USD", wantErr: ErrInvalidMoney},
+		{name: "thousands separator", value: "1,000.00", currency: "USD", wantErr: ErrInvalidMoney},
+		{name: "whitespace", value: " 1.00", currency: "USD", wantErr: ErrInvalidMoney},
+		{name: "unknown currency", value: "1.00", currency: "BANANA", wantErr: ErrUnknownCurrency},
+		{name: "no currency", value: "1.00", currency: "", wantErr: ErrUnknownCurrency},
+		{name: "lower-case currency", value: "1.00", currency: "usd", wantErr: ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			money, err := ParseMoney(tt.value, tt.currency)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, NewMoney(tt.want, tt.currency), money)
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: NewMoney(1500, "JPY"), want: "1500"},
+		{money: NewMoney(1050, "USD"), want: "10.50"},
+		{money: NewMoney(5, "USD"), want: "0.05"},
+		{money: NewMoney(0, "USD"), want: "0.00"},
+		{money: NewMoney(-1050, "USD"), want: "-10.50"},
+		{money: NewMoney(-5, "USD"), want: "-0.05"},
+		{money: NewMoney(1234, "KWD"), want: "1.234"},
+		{money: NewMoney(1, "CLF"), want: "0.0001"},
+		{money: NewMoney(math.MaxInt64, "USD"), want: "92233720368547758.07"},
+		{money: NewMoney(math.MinInt64, "USD"), want: "-92233720368547758.08"},
+		{money: NewMoney(1050, "BANANA"), want: "1050"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want+" "+tt.money.Currency, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.money.String())
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Money
+		wantErr error
+	}{
+		{name: "decimal string", data: `{"amount": "10.50", "currency": "USD"}`, want: NewMoney(1050, "USD")},
+		{name: "JSON number", data: `{"amount": 10.5, "currency": "USD"}`, want: NewMoney(1050, "USD")},
+		{name: "large JSON number is not rounded", data: `{"amount": 92233720368547758.07, "currency": "USD"}`,
+			want: NewMoney(math.MaxInt64, "USD")},
+		{name: "JSON number with an exponent", data: `{"amount": 1e3, "currency": "USD"}`, wantErr: ErrInvalidMoney},
+		{name: "too many decimals", data: `{"amount": "10.505", "currency": "USD"}`, wantErr: ErrInvalidMoney},
+		{name: "missing amount", data: `{"currency": "USD"}`, wantErr: ErrInvalidMoney},
+		{name: "missing currency", data: `{"amount": "10.50"}`, wantErr: ErrUnknownCurrency},
+		{name: "null", data: `null`, want: Money{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var money Money
+
+			err := json.Unmarshal([]byte(tt.data), &money)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, money)
+		})
+	}
+
+	t.Run("unknown member", func(t *testing.T) {
+		var money Money
+
+		err := json.Unmarshal([]byte(`{"amount": "10.50", "currency": "USD", "rate": 1}`), &money)
+		require.ErrorContains(t, err, `unknown field "rate"`)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		for _, money := range []Money{NewMoney(1050, "USD"), NewMoney(-1, "KWD"), NewMoney(math.MaxInt64, "JPY")} {
+			data, err := json.Marshal(money)
+			require.NoError(t, err)
+
+			var decoded Money
+
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			require.Equal(t, money, decoded)
+		}
+	})
+
+	t.Run("amount is a decimal string", func(t *testing.T) {
+		data, err := json.Marshal(NewMoney(1050, "USD"))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"amount": "10.50", "currency": "USD"}`, string(data))
+	})
+
+	t.Run("null balance is omitted", func(t *testing.T) {
+		var info WalletInfo
+
+		require.NoError(t, json.Unmarshal([]byte(`{"name": "wallet", "currency": "USD", "balance": null}`), &info))
+		require.Equal(t, Money{}, info.Balance)
+	})
+}
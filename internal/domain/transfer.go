@@ -0,0 +1,19 @@
+package domain
+
+import "github.com/google/uuid"
+
+var (
+	ErrCurrencyMismatch = NewError(KindValidation, "currency_mismatch", "currencies do not match")
+	ErrSameWallet       = NewError(KindValidation, "same_wallet", "source and destination wallets must differ")
+)
+
+type Transfer struct {
+	FromWalletId uuid.UUID `json:"fromWalletId"`
+	ToWalletId   uuid.UUID `json:"toWalletId"`
+	Amount       Money     `json:"amount"`
+}
+
+type TransferResult struct {
+	From Wallet `json:"from"`
+	To   Wallet `json:"to"`
+}
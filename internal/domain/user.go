@@ -11,3 +11,10 @@ type User struct {
 	BlockedAt *time.Time `json:"blockedAt" db:"blocked_at"`
 	DeletedAt *time.Time `json:"deletedAt" db:"deleted_at"`
 }
+
+// UserChange is the state of a user reported by an event that occurred at
+// EventAt.
+type UserChange struct {
+	User    User
+	EventAt time.Time
+}
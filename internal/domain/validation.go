@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Lengths are counted in characters, as VARCHAR columns count them.
+const (
+	MaxWalletNameLength        = 255
+	MaxWalletDescriptionLength = 1000
+	MaxWalletTags              = 20
+	MaxWalletTagLength         = 50
+)
+
+// FieldErrors collects the invalid fields of a request so that all of them are
+// reported together.
+type FieldErrors []FieldError
+
+func (f *FieldErrors) Add(field, message string) {
+	if message != "" {
+		*f = append(*f, FieldError{Field: field, Message: message})
+	}
+}
+
+// Err returns nil when no field was invalid.
+func (f FieldErrors) Err() error {
+	if len(f) == 0 {
+		return nil
+	}
+
+	return NewFieldsError(f)
+}
+
+// Validate checks a wallet about to be created.
+func (w Wallet) Validate() error {
+	var fields FieldErrors
+
+	fields.Add("name", validateWalletName(w.Name))
+	fields.Add("description", validateWalletDescription(w.Description))
+	fields.Add("tags", validateWalletTags(w.Tags))
+
+	if !IsKnownCurrency(w.Currency) {
+		fields.Add("currency", "must be an ISO 4217 currency code")
+	}
+
+	switch {
+	case w.Balance.IsNegative():
+		fields.Add("balance", "must not be negative")
+	case w.Balance.Currency != w.Currency:
+		fields.Add("balance", "must be in the wallet currency")
+	}
+
+	return fields.Err()
+}
+
+// The validators below return why the value is invalid, or "" if it is valid.
+
+func validateWalletName(name string) string {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return "must not be empty"
+	case utf8.RuneCountInString(name) > MaxWalletNameLength:
+		return fmt.Sprintf("must be at most %d characters long", MaxWalletNameLength)
+	default:
+		return ""
+	}
+}
+
+func validateWalletDescription(description string) string {
+	if utf8.RuneCountInString(description) > MaxWalletDescriptionLength {
+		return fmt.Sprintf("must be at most %d characters long", MaxWalletDescriptionLength)
+	}
+
+	return ""
+}
+
+func validateWalletTags(tags []string) string {
+	if len(tags) > MaxWalletTags {
+		return fmt.Sprintf("must hold at most %d tags", MaxWalletTags)
+	}
+
+	for i, tag := range tags {
+		switch {
+		case strings.TrimSpace(tag) == "":
+			return "must not contain empty tags"
+		case utf8.RuneCountInString(tag) > MaxWalletTagLength:
+			return fmt.Sprintf("must not contain tags longer than %d characters", MaxWalletTagLength)
+		case slices.Contains(tags[:i], tag):
+			return "must not contain duplicate tags"
+		}
+	}
+
+	return ""
+}
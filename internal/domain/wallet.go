@@ -6,26 +6,98 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	ErrInsufficientFunds = NewError(KindInsufficientFunds, "insufficient_funds", "insufficient funds")
+	ErrWalletNotFound    = NewError(KindNotFound, "wallet_not_found", "wallet not found")
+	ErrInvalidAmount     = NewError(KindValidation, "invalid_amount", "amount must be greater than zero")
+	ErrWalletFrozen      = NewError(KindForbidden, "wallet_frozen", "wallet is frozen")
+
+	ErrWalletVersionMismatch = NewError(KindPreconditionFailed, "version_mismatch",
+		"wallet was changed by another request")
+)
+
+// AnyVersion stands for whatever version a wallet is at. Stored versions start
+// at 1 and grow with every change to the wallet.
+const AnyVersion int64 = 0
+
+type WalletStatus string
+
+const (
+	WalletActive WalletStatus = "active"
+	WalletFrozen WalletStatus = "frozen"
+)
+
 type WalletId uuid.UUID
 
 type Wallet struct {
-	Id        uuid.UUID  `json:"-"         db:"id"`
-	UserId    string     `json:"-"         db:"user_id"`
-	Name      string     `json:"name"      db:"name"`
-	Balance   float64    `json:"balance"   db:"balance"`
-	Currency  string     `json:"currency"  db:"currency"`
-	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
-	UpdatedAt time.Time  `json:"updatedAt" db:"updated_at"`
-	DeletedAt *time.Time `json:"deletedAt" db:"deleted_at"`
+	Id                 uuid.UUID    `json:"id"                 db:"id"`
+	UserId             string       `json:"-"                  db:"user_id"`
+	Name               string       `json:"name"               db:"name"`
+	Balance            Money        `json:"balance"            db:"balance"`
+	Currency           string       `json:"currency"           db:"currency"`
+	Description        string       `json:"description"        db:"description"`
+	Tags               []string     `json:"tags"               db:"tags"`
+	IsDefault          bool         `json:"isDefault"          db:"is_default"`
+	Status             WalletStatus `json:"status"             db:"status"`
+	ClosureRequestedAt *time.Time   `json:"closureRequestedAt" db:"closure_requested_at"`
+	Version            int64        `json:"version"            db:"version"`
+	CreatedAt          time.Time    `json:"createdAt"          db:"created_at"`
+	UpdatedAt          time.Time    `json:"updatedAt"          db:"updated_at"`
+	DeletedAt          *time.Time   `json:"deletedAt"          db:"deleted_at"`
 }
 
 type WalletInfo struct {
-	Name     string  `json:"name"`
-	Balance  float64 `json:"balance"`
-	Currency string  `json:"currency"`
+	Name     string `json:"name"`
+	Balance  Money  `json:"balance,omitzero"`
+	Currency string `json:"currency"`
+}
+
+type BalanceChange struct {
+	Amount Money `json:"amount"`
+}
+
+type WalletSort string
+
+const (
+	WalletSortCreatedAt WalletSort = "created_at"
+	WalletSortName      WalletSort = "name"
+	WalletSortBalance   WalletSort = "balance"
+)
+
+func (s WalletSort) Valid() bool {
+	switch s {
+	case WalletSortCreatedAt, WalletSortName, WalletSortBalance:
+		return true
+	default:
+		return false
+	}
+}
+
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+func (o SortOrder) Valid() bool {
+	return o == SortAsc || o == SortDesc
+}
+
+// WalletFilter selects a page of a user's wallets. Balance bounds are inclusive
+// and only make sense within one currency, so they require Currency.
+type WalletFilter struct {
+	Limit      int
+	Cursor     string
+	Currency   string
+	NamePrefix string
+	MinBalance *Money
+	MaxBalance *Money
+	Sort       WalletSort
+	Order      SortOrder
 }
 
-type WalletUpdate struct {
-	Id   string `json:"-"`
-	Name string `json:"name"`
+type WalletPage struct {
+	Wallets    []Wallet `json:"wallets"`
+	NextCursor string   `json:"nextCursor,omitempty"`
 }
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WalletEventType string
+
+const (
+	WalletCreated        WalletEventType = "wallet.created"
+	WalletUpdated        WalletEventType = "wallet.updated"
+	WalletDeleted        WalletEventType = "wallet.deleted"
+	WalletBalanceChanged WalletEventType = "wallet.balance_changed"
+	WalletFrozenEvent    WalletEventType = "wallet.frozen"
+)
+
+// WalletRenamed is no longer published, renames are WalletUpdated events. It is
+// kept to read the events published before.
+const WalletRenamed WalletEventType = "wallet.renamed"
+
+// WalletEvent is published to the wallet events topic after every wallet state
+// change and carries the wallet as it was right after the change.
+type WalletEvent struct {
+	Id            uuid.UUID       `json:"id"`
+	Type          WalletEventType `json:"type"`
+	WalletId      uuid.UUID       `json:"walletId"`
+	UserId        string          `json:"userId"`
+	TransactionId *uuid.UUID      `json:"transactionId,omitempty"`
+	OccurredAt    time.Time       `json:"occurredAt"`
+	Wallet        Wallet          `json:"wallet"`
+}
+
+func NewWalletEvent(eventType WalletEventType, wallet Wallet) WalletEvent {
+	return WalletEvent{
+		Id:         uuid.New(),
+		Type:       eventType,
+		WalletId:   wallet.Id,
+		UserId:     wallet.UserId,
+		OccurredAt: time.Now().UTC(),
+		Wallet:     wallet,
+	}
+}
+
+// OutboxMessage is a wallet event stored alongside the change that produced it
+// and waiting to be relayed to Kafka.
+type OutboxMessage struct {
+	Id          int64           `db:"id"`
+	AggregateId uuid.UUID       `db:"aggregate_id"`
+	EventType   WalletEventType `db:"event_type"`
+	Payload     []byte          `db:"payload"`
+	CreatedAt   time.Time       `db:"created_at"`
+	SentAt      *time.Time      `db:"sent_at"`
+}
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+)
+
+var ErrInvalidPatch = NewError(KindValidation, "invalid_patch", "merge patch must be a JSON object")
+
+// walletImmutableFields can be read but never patched: the balance only moves
+// through the ledger and the rest is maintained by the service.
+var walletImmutableFields = []string{
+	"id", "balance", "currency", "closureRequestedAt", "version", "createdAt", "updatedAt", "deletedAt",
+}
+
+// WalletPatch is a JSON merge patch (RFC 7396) of the mutable wallet fields. A
+// nil field is left as it is. Fields that may be empty are reset by null.
+type WalletPatch struct {
+	Name        *string       `json:"name,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	Tags        *[]string     `json:"tags,omitempty"`
+	Status      *WalletStatus `json:"status,omitempty"`
+	IsDefault   *bool         `json:"isDefault,omitempty"`
+}
+
+func (p WalletPatch) Empty() bool {
+	return p == WalletPatch{}
+}
+
+// ParseWalletPatch reads a merge patch document. Every field it cannot apply is
+// reported in the returned error rather than only the first one.
+func ParseWalletPatch(data []byte) (WalletPatch, error) {
+	var document map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &document); err != nil || document == nil {
+		return WalletPatch{}, ErrInvalidPatch
+	}
+
+	var (
+		patch  WalletPatch
+		fields FieldErrors
+	)
+
+	for _, field := range slices.Sorted(maps.Keys(document)) {
+		value := document[field]
+		null := bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+
+		var message string
+
+		switch field {
+		case "name":
+			patch.Name, message = patchString(value, null)
+			if message == "" {
+				message = validateWalletName(*patch.Name)
+			}
+		case "description":
+			if null {
+				patch.Description = new(string)
+			} else {
+				patch.Description, message = patchString(value, null)
+			}
+
+			if message == "" {
+				message = validateWalletDescription(*patch.Description)
+			}
+		case "tags":
+			patch.Tags, message = patchTags(value, null)
+		case "status":
+			patch.Status, message = patchStatus(value, null)
+		case "isDefault":
+			patch.IsDefault, message = patchBool(value, null)
+		default:
+			message = "is not a wallet field"
+			if slices.Contains(walletImmutableFields, field) {
+				message = "is immutable"
+			}
+		}
+
+		fields.Add(field, message)
+	}
+
+	if err := fields.Err(); err != nil {
+		return WalletPatch{}, err
+	}
+
+	return patch, nil
+}
+
+func patchString(value json.RawMessage, null bool) (*string, string) {
+	var parsed string
+
+	if null || json.Unmarshal(value, &parsed) != nil {
+		return nil, "must be a string"
+	}
+
+	return &parsed, ""
+}
+
+func patchTags(value json.RawMessage, null bool) (*[]string, string) {
+	tags := []string{}
+
+	if null {
+		return &tags, ""
+	}
+
+	if json.Unmarshal(value, &tags) != nil {
+		return nil, "must be an array of strings"
+	}
+
+	if message := validateWalletTags(tags); message != "" {
+		return nil, message
+	}
+
+	return &tags, ""
+}
+
+func patchStatus(value json.RawMessage, null bool) (*WalletStatus, string) {
+	var status WalletStatus
+
+	if null || json.Unmarshal(value, &status) != nil || (status != WalletActive && status != WalletFrozen) {
+		return nil, `must be "active" or "frozen"`
+	}
+
+	return &status, ""
+}
+
+func patchBool(value json.RawMessage, null bool) (*bool, string) {
+	var parsed bool
+
+	if !null && json.Unmarshal(value, &parsed) != nil {
+		return nil, "must be a boolean"
+	}
+
+	return &parsed, ""
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWalletPatch(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	status := func(s WalletStatus) *WalletStatus { return &s }
+	flag := func(b bool) *bool { return &b }
+	tags := func(t ...string) *[]string {
+		t = append([]string{}, t...)
+
+		return &t
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want WalletPatch
+	}{
+		{name: "empty object", data: `{}`, want: WalletPatch{}},
+		{name: "name", data: `{"name": "savings"}`, want: WalletPatch{Name: ptr("savings")}},
+		{name: "description", data: `{"description": "rainy day"}`, want: WalletPatch{Description: ptr("rainy day")}},
+		{name: "null description is cleared", data: `{"description": null}`, want: WalletPatch{Description: ptr("")}},
+		{name: "tags", data: `{"tags": ["home", "family"]}`, want: WalletPatch{Tags: tags("home", "family")}},
+		{name: "null tags are cleared", data: `{"tags": null}`, want: WalletPatch{Tags: tags()}},
+		{name: "empty tags are cleared", data: `{"tags": []}`, want: WalletPatch{Tags: tags()}},
+		{name: "status", data: `{"status": "frozen"}`, want: WalletPatch{Status: status(WalletFrozen)}},
+		{name: "isDefault", data: `{"isDefault": true}`, want: WalletPatch{IsDefault: flag(true)}},
+		{name: "null isDefault is false", data: `{"isDefault": null}`, want: WalletPatch{IsDefault: flag(false)}},
+		{
+			name: "several fields",
+			data: `{"name": "savings", "tags": null, "status": "active"}`,
+			want: WalletPatch{Name: ptr("savings"), Tags: tags(), Status: status(WalletActive)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := ParseWalletPatch([]byte(tt.data))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, patch)
+			require.Equal(t, tt.data == `{}`, patch.Empty())
+		})
+	}
+}
+
+func TestParseWalletPatchNotAnObject(t *testing.T) {
+	for _, data := range []string{``, `null`, `[]`, `[{"name": "savings"}]`, `"savings"`, `42`, `true`, `{"name":`} {
+		t.Run(data, func(t *testing.T) {
+			_, err := ParseWalletPatch([]byte(data))
+			require.ErrorIs(t, err, ErrInvalidPatch)
+		})
+	}
+}
+
+func TestParseWalletPatchInvalidFields(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []FieldError
+	}{
+		{
+			name: "null name",
+			data: `{"name": null}`,
+			want: []FieldError{{Field: "name", Message: "must be a string"}},
+		},
+		{
+			name: "blank name",
+			data: `{"name": "  "}`,
+			want: []FieldError{{Field: "name", Message: "must not be empty"}},
+		},
+		{
+			name: "long description",
+			data: `{"description": "` + strings.Repeat("a", MaxWalletDescriptionLength+1) + `"}`,
+			want: []FieldError{{Field: "description", Message: "must be at most 1000 characters long"}},
+		},
+		{
+			name: "duplicate tags",
+			data: `{"tags": ["home", "home"]}`,
+			want: []FieldError{{Field: "tags", Message: "must not contain duplicate tags"}},
+		},
+		{
+			name: "null status",
+			data: `{"status": null}`,
+			want: []FieldError{{Field: "status", Message: `must be "active" or "frozen"`}},
+		},
+		{
+			name: "closed status",
+			data: `{"status": "closed"}`,
+			want: []FieldError{{Field: "status", Message: `must be "active" or "frozen"`}},
+		},
+		{
+			name: "nested object is not merged",
+			data: `{"name": {"first": "savings"}, "tags": {"home": true}}`,
+			want: []FieldError{
+				{Field: "name", Message: "must be a string"},
+				{Field: "tags", Message: "must be an array of strings"},
+			},
+		},
+		{
+			name: "string isDefault",
+			data: `{"isDefault": "true"}`,
+			want: []FieldError{{Field: "isDefault", Message: "must be a boolean"}},
+		},
+		{
+			name: "unknown member",
+			data: `{"nickname": "savings"}`,
+			want: []FieldError{{Field: "nickname", Message: "is not a wallet field"}},
+		},
+		{
+			name: "immutable members, even when null",
+			data: `{"balance": null, "currency": "EUR", "id": "1"}`,
+			want: []FieldError{
+				{Field: "balance", Message: "is immutable"},
+				{Field: "currency", Message: "is immutable"},
+				{Field: "id", Message: "is immutable"},
+			},
+		},
+		{
+			name: "all invalid fields are reported in order",
+			data: `{"version": 2, "name": "", "status": "deleted", "color": "red"}`,
+			want: []FieldError{
+				{Field: "color", Message: "is not a wallet field"},
+				{Field: "name", Message: "must not be empty"},
+				{Field: "status", Message: `must be "active" or "frozen"`},
+				{Field: "version", Message: "is immutable"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := ParseWalletPatch([]byte(tt.data))
+			require.Error(t, err)
+			require.True(t, patch.Empty())
+
+			var domainErr *Error
+
+			require.True(t, errors.As(err, &domainErr))
+			require.Equal(t, KindValidation, domainErr.Kind)
+			require.Equal(t, tt.want, domainErr.Fields)
+		})
+	}
+}
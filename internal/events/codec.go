@@ -0,0 +1,140 @@
+package events
+
+//go:generate go run github.com/bufbuild/buf/cmd/buf@v1.50.0 generate proto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/events/eventspb"
+)
+
+// Kafka message headers describing an event. HeaderContentType tells which
+// codec the message value was encoded with; a message without it is JSON.
+const (
+	HeaderContentType   = "content-type"
+	HeaderEventType     = "event-type"
+	HeaderSchemaVersion = "schema-version"
+	HeaderCorrelationId = "correlation-id"
+)
+
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// Encode encodes a UserEvent or a domain.WalletEvent with the codec of the
+// content type.
+func Encode(event any, contentType string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	switch contentType {
+	case ContentTypeJSON, "":
+		data, err = json.Marshal(event)
+	case ContentTypeProtobuf:
+		var msg proto.Message
+
+		switch event := event.(type) {
+		case UserEvent:
+			msg = userEventToProto(event)
+		case domain.WalletEvent:
+			msg = walletEventToProto(event)
+		default:
+			return nil, fmt.Errorf("failed to encode %T: not an event", event)
+		}
+
+		data, err = proto.Marshal(msg)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %T: %w", event, err)
+	}
+
+	return data, nil
+}
+
+// DecodeUserEvent reads the schema version before trusting the rest of the
+// event, so a message written with a layout this build does not know is
+// rejected rather than decoded into zero values.
+func DecodeUserEvent(data []byte, contentType string) (UserEvent, error) {
+	var event UserEvent
+
+	switch contentType {
+	case ContentTypeJSON, "":
+		var version struct {
+			SchemaVersion int `json:"schemaVersion"`
+		}
+
+		if err := json.Unmarshal(data, &version); err != nil {
+			return UserEvent{}, fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+		}
+
+		if version.SchemaVersion != UserSchemaVersion {
+			return UserEvent{}, fmt.Errorf("%w: %d", ErrUnsupportedSchemaVersion, version.SchemaVersion)
+		}
+
+		if err := json.Unmarshal(data, &event); err != nil {
+			return UserEvent{}, fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+		}
+	case ContentTypeProtobuf:
+		var msg eventspb.UserEvent
+
+		if err := proto.Unmarshal(data, &msg); err != nil {
+			return UserEvent{}, fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+		}
+
+		if msg.GetSchemaVersion() != UserSchemaVersion {
+			return UserEvent{}, fmt.Errorf("%w: %d", ErrUnsupportedSchemaVersion, msg.GetSchemaVersion())
+		}
+
+		var err error
+
+		event, err = userEventFromProto(&msg)
+		if err != nil {
+			return UserEvent{}, err
+		}
+	default:
+		return UserEvent{}, fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+	}
+
+	if err := event.Validate(); err != nil {
+		return UserEvent{}, err
+	}
+
+	return event, nil
+}
+
+func DecodeWalletEvent(data []byte, contentType string) (domain.WalletEvent, error) {
+	switch contentType {
+	case ContentTypeJSON, "":
+		var event domain.WalletEvent
+
+		if err := json.Unmarshal(data, &event); err != nil {
+			return domain.WalletEvent{}, fmt.Errorf("failed to decode the wallet event: %w", err)
+		}
+
+		event.Wallet.UserId = event.UserId
+
+		return event, nil
+	case ContentTypeProtobuf:
+		var msg eventspb.WalletEvent
+
+		if err := proto.Unmarshal(data, &msg); err != nil {
+			return domain.WalletEvent{}, fmt.Errorf("failed to decode the wallet event: %w", err)
+		}
+
+		return walletEventFromProto(&msg)
+	default:
+		return domain.WalletEvent{}, fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+	}
+}
@@ -0,0 +1,199 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"wallet-service/internal/domain"
+)
+
+var occurredAt = time.Date(2025, 3, 14, 15, 9, 26, 535897932, time.UTC)
+
+func userEvent(eventType UserEventType) UserEvent {
+	event := NewUserEvent(eventType, domain.User{Id: uuid.New()})
+	event.OccurredAt = occurredAt
+
+	switch eventType {
+	case UserBlocked:
+		event.Payload.BlockedAt = &occurredAt
+	case UserDeleted:
+		event.Payload.DeletedAt = &occurredAt
+	}
+
+	return event
+}
+
+func walletEvent() domain.WalletEvent {
+	transactionId := uuid.New()
+	createdAt := occurredAt.Add(-time.Hour)
+
+	event := domain.NewWalletEvent(domain.WalletBalanceChanged, domain.Wallet{
+		Id:                 uuid.New(),
+		UserId:             uuid.NewString(),
+		Name:               "savings",
+		Balance:            domain.NewMoney(12345, "USD"),
+		Currency:           "USD",
+		Description:        "rainy day",
+		Tags:               []string{"home", "family"},
+		IsDefault:          true,
+		Status:             domain.WalletActive,
+		ClosureRequestedAt: &occurredAt,
+		Version:            7,
+		CreatedAt:          createdAt,
+		UpdatedAt:          occurredAt,
+	})
+	event.OccurredAt = occurredAt
+	event.TransactionId = &transactionId
+
+	return event
+}
+
+var contentTypes = []string{ContentTypeJSON, ContentTypeProtobuf}
+
+func TestDecodeUserEventSchemaVersion(t *testing.T) {
+	tests := []struct {
+		version int
+		wantErr error
+	}{
+		{version: 0, wantErr: ErrUnsupportedSchemaVersion},
+		{version: UserSchemaVersion},
+		{version: UserSchemaVersion + 1, wantErr: ErrUnsupportedSchemaVersion},
+	}
+
+	for _, contentType := range contentTypes {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s version %d", contentType, tt.version), func(t *testing.T) {
+				event := userEvent(UserCreated)
+				event.SchemaVersion = tt.version
+
+				data, err := Encode(event, contentType)
+				require.NoError(t, err)
+
+				decoded, err := DecodeUserEvent(data, contentType)
+				if tt.wantErr != nil {
+					require.ErrorIs(t, err, tt.wantErr)
+
+					return
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, event, decoded)
+			})
+		}
+	}
+}
+
+func TestDecodeUserEventUnknownType(t *testing.T) {
+	for _, contentType := range contentTypes {
+		t.Run(contentType, func(t *testing.T) {
+			event := userEvent(UserCreated)
+			event.Type = "user.renamed"
+
+			data, err := Encode(event, contentType)
+			require.NoError(t, err)
+
+			_, err = DecodeUserEvent(data, contentType)
+			require.ErrorIs(t, err, ErrUnknownEventType)
+		})
+	}
+}
+
+func TestCodecByContentType(t *testing.T) {
+	event := userEvent(UserCreated)
+
+	jsonData, err := Encode(event, ContentTypeJSON)
+	require.NoError(t, err)
+	require.True(t, json.Valid(jsonData))
+
+	protoData, err := Encode(event, ContentTypeProtobuf)
+	require.NoError(t, err)
+	require.False(t, json.Valid(protoData))
+
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		wantErr     error
+	}{
+		{name: "JSON", data: jsonData, contentType: ContentTypeJSON},
+		{name: "no content type is JSON", data: jsonData, contentType: ""},
+		{name: "protobuf", data: protoData, contentType: ContentTypeProtobuf},
+		{name: "protobuf read as JSON", data: protoData, contentType: ContentTypeJSON, wantErr: ErrInvalidEvent},
+		{name: "JSON read as protobuf", data: jsonData, contentType: ContentTypeProtobuf, wantErr: ErrInvalidEvent},
+		{name: "unsupported content type", data: jsonData, contentType: "application/xml", wantErr: ErrUnsupportedContentType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeUserEvent(tt.data, tt.contentType)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, event, decoded)
+		})
+	}
+
+	t.Run("encode unsupported content type", func(t *testing.T) {
+		_, err := Encode(event, "application/xml")
+		require.ErrorIs(t, err, ErrUnsupportedContentType)
+	})
+
+	t.Run("decode wallet event unsupported content type", func(t *testing.T) {
+		_, err := DecodeWalletEvent(jsonData, "application/xml")
+		require.ErrorIs(t, err, ErrUnsupportedContentType)
+	})
+}
+
+func TestUserEventRoundTrip(t *testing.T) {
+	for _, eventType := range []UserEventType{UserCreated, UserBlocked, UserUnblocked, UserDeleted} {
+		t.Run(string(eventType), func(t *testing.T) {
+			event := userEvent(eventType)
+
+			// JSON -> protobuf -> JSON must not lose anything on the way.
+			decoded := event
+
+			for _, contentType := range []string{ContentTypeJSON, ContentTypeProtobuf, ContentTypeJSON} {
+				data, err := Encode(decoded, contentType)
+				require.NoError(t, err)
+
+				decoded, err = DecodeUserEvent(data, contentType)
+				require.NoError(t, err)
+				require.Equal(t, event, decoded, contentType)
+			}
+		})
+	}
+}
+
+func TestWalletEventRoundTrip(t *testing.T) {
+	event := walletEvent()
+	decoded := event
+
+	for _, contentType := range []string{ContentTypeJSON, ContentTypeProtobuf, ContentTypeJSON} {
+		data, err := Encode(decoded, contentType)
+		require.NoError(t, err)
+
+		decoded, err = DecodeWalletEvent(data, contentType)
+		require.NoError(t, err)
+		require.Equal(t, event, decoded, contentType)
+	}
+
+	t.Run("without a transaction", func(t *testing.T) {
+		event := walletEvent()
+		event.TransactionId = nil
+
+		data, err := Encode(event, ContentTypeProtobuf)
+		require.NoError(t, err)
+
+		decoded, err := DecodeWalletEvent(data, ContentTypeProtobuf)
+		require.NoError(t, err)
+		require.Nil(t, decoded.TransactionId)
+	})
+}
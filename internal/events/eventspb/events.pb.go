@@ -0,0 +1,700 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: events.proto
+
+package eventspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type UserEventType int32
+
+const (
+	UserEventType_USER_EVENT_TYPE_UNSPECIFIED UserEventType = 0
+	UserEventType_USER_EVENT_TYPE_CREATED     UserEventType = 1
+	UserEventType_USER_EVENT_TYPE_BLOCKED     UserEventType = 2
+	UserEventType_USER_EVENT_TYPE_UNBLOCKED   UserEventType = 3
+	UserEventType_USER_EVENT_TYPE_DELETED     UserEventType = 4
+)
+
+// Enum value maps for UserEventType.
+var (
+	UserEventType_name = map[int32]string{
+		0: "USER_EVENT_TYPE_UNSPECIFIED",
+		1: "USER_EVENT_TYPE_CREATED",
+		2: "USER_EVENT_TYPE_BLOCKED",
+		3: "USER_EVENT_TYPE_UNBLOCKED",
+		4: "USER_EVENT_TYPE_DELETED",
+	}
+	UserEventType_value = map[string]int32{
+		"USER_EVENT_TYPE_UNSPECIFIED": 0,
+		"USER_EVENT_TYPE_CREATED":     1,
+		"USER_EVENT_TYPE_BLOCKED":     2,
+		"USER_EVENT_TYPE_UNBLOCKED":   3,
+		"USER_EVENT_TYPE_DELETED":     4,
+	}
+)
+
+func (x UserEventType) Enum() *UserEventType {
+	p := new(UserEventType)
+	*p = x
+	return p
+}
+
+func (x UserEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UserEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_events_proto_enumTypes[0].Descriptor()
+}
+
+func (UserEventType) Type() protoreflect.EnumType {
+	return &file_events_proto_enumTypes[0]
+}
+
+func (x UserEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UserEventType.Descriptor instead.
+func (UserEventType) EnumDescriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{0}
+}
+
+type WalletEventType int32
+
+const (
+	WalletEventType_WALLET_EVENT_TYPE_UNSPECIFIED     WalletEventType = 0
+	WalletEventType_WALLET_EVENT_TYPE_CREATED         WalletEventType = 1
+	WalletEventType_WALLET_EVENT_TYPE_RENAMED         WalletEventType = 2
+	WalletEventType_WALLET_EVENT_TYPE_DELETED         WalletEventType = 3
+	WalletEventType_WALLET_EVENT_TYPE_BALANCE_CHANGED WalletEventType = 4
+	WalletEventType_WALLET_EVENT_TYPE_FROZEN          WalletEventType = 5
+	WalletEventType_WALLET_EVENT_TYPE_UPDATED         WalletEventType = 6
+)
+
+// Enum value maps for WalletEventType.
+var (
+	WalletEventType_name = map[int32]string{
+		0: "WALLET_EVENT_TYPE_UNSPECIFIED",
+		1: "WALLET_EVENT_TYPE_CREATED",
+		2: "WALLET_EVENT_TYPE_RENAMED",
+		3: "WALLET_EVENT_TYPE_DELETED",
+		4: "WALLET_EVENT_TYPE_BALANCE_CHANGED",
+		5: "WALLET_EVENT_TYPE_FROZEN",
+		6: "WALLET_EVENT_TYPE_UPDATED",
+	}
+	WalletEventType_value = map[string]int32{
+		"WALLET_EVENT_TYPE_UNSPECIFIED":     0,
+		"WALLET_EVENT_TYPE_CREATED":         1,
+		"WALLET_EVENT_TYPE_RENAMED":         2,
+		"WALLET_EVENT_TYPE_DELETED":         3,
+		"WALLET_EVENT_TYPE_BALANCE_CHANGED": 4,
+		"WALLET_EVENT_TYPE_FROZEN":          5,
+		"WALLET_EVENT_TYPE_UPDATED":         6,
+	}
+)
+
+func (x WalletEventType) Enum() *WalletEventType {
+	p := new(WalletEventType)
+	*p = x
+	return p
+}
+
+func (x WalletEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WalletEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_events_proto_enumTypes[1].Descriptor()
+}
+
+func (WalletEventType) Type() protoreflect.EnumType {
+	return &file_events_proto_enumTypes[1]
+}
+
+func (x WalletEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WalletEventType.Descriptor instead.
+func (WalletEventType) EnumDescriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{1}
+}
+
+// UserEvent is published to the users topic. The payload carries the full
+// user state after the event.
+type UserEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Type          UserEventType          `protobuf:"varint,2,opt,name=type,proto3,enum=wallet.events.v1.UserEventType" json:"type,omitempty"`
+	SchemaVersion uint32                 `protobuf:"varint,3,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	Payload       *User                  `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserEvent) Reset() {
+	*x = UserEvent{}
+	mi := &file_events_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserEvent) ProtoMessage() {}
+
+func (x *UserEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserEvent.ProtoReflect.Descriptor instead.
+func (*UserEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UserEvent) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *UserEvent) GetType() UserEventType {
+	if x != nil {
+		return x.Type
+	}
+	return UserEventType_USER_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *UserEvent) GetSchemaVersion() uint32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *UserEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *UserEvent) GetPayload() *User {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	BlockedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=blocked_at,json=blockedAt,proto3" json:"blocked_at,omitempty"`
+	DeletedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_events_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *User) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *User) GetBlockedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.BlockedAt
+	}
+	return nil
+}
+
+func (x *User) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+// WalletEvent is published to the wallet events topic after every wallet state
+// change and carries the wallet as it was right after the change.
+type WalletEvent struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	EventId  string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Type     WalletEventType        `protobuf:"varint,2,opt,name=type,proto3,enum=wallet.events.v1.WalletEventType" json:"type,omitempty"`
+	WalletId string                 `protobuf:"bytes,3,opt,name=wallet_id,json=walletId,proto3" json:"wallet_id,omitempty"`
+	UserId   string                 `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Empty unless the change was caused by a ledger transaction.
+	TransactionId string                 `protobuf:"bytes,5,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	Wallet        *Wallet                `protobuf:"bytes,7,opt,name=wallet,proto3" json:"wallet,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WalletEvent) Reset() {
+	*x = WalletEvent{}
+	mi := &file_events_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WalletEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalletEvent) ProtoMessage() {}
+
+func (x *WalletEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalletEvent.ProtoReflect.Descriptor instead.
+func (*WalletEvent) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WalletEvent) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *WalletEvent) GetType() WalletEventType {
+	if x != nil {
+		return x.Type
+	}
+	return WalletEventType_WALLET_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *WalletEvent) GetWalletId() string {
+	if x != nil {
+		return x.WalletId
+	}
+	return ""
+}
+
+func (x *WalletEvent) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *WalletEvent) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *WalletEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *WalletEvent) GetWallet() *Wallet {
+	if x != nil {
+		return x.Wallet
+	}
+	return nil
+}
+
+type Wallet struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId             string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name               string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Balance            *Money                 `protobuf:"bytes,4,opt,name=balance,proto3" json:"balance,omitempty"`
+	Status             string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	ClosureRequestedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=closure_requested_at,json=closureRequestedAt,proto3" json:"closure_requested_at,omitempty"`
+	CreatedAt          *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt          *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DeletedAt          *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	Description        string                 `protobuf:"bytes,10,opt,name=description,proto3" json:"description,omitempty"`
+	Tags               []string               `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
+	IsDefault          bool                   `protobuf:"varint,12,opt,name=is_default,json=isDefault,proto3" json:"is_default,omitempty"`
+	Version            int64                  `protobuf:"varint,13,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Wallet) Reset() {
+	*x = Wallet{}
+	mi := &file_events_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Wallet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Wallet) ProtoMessage() {}
+
+func (x *Wallet) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Wallet.ProtoReflect.Descriptor instead.
+func (*Wallet) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Wallet) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Wallet) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Wallet) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Wallet) GetBalance() *Money {
+	if x != nil {
+		return x.Balance
+	}
+	return nil
+}
+
+func (x *Wallet) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Wallet) GetClosureRequestedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClosureRequestedAt
+	}
+	return nil
+}
+
+func (x *Wallet) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Wallet) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Wallet) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *Wallet) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Wallet) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Wallet) GetIsDefault() bool {
+	if x != nil {
+		return x.IsDefault
+	}
+	return false
+}
+
+func (x *Wallet) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// Money is an amount in the minor units of its ISO 4217 currency, e.g. cents.
+type Money struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Amount        int64                  `protobuf:"varint,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency      string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_events_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_events_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_events_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Money) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Money) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+var File_events_proto protoreflect.FileDescriptor
+
+const file_events_proto_rawDesc = "" +
+	"\n" +
+	"\fevents.proto\x12\x10wallet.events.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf1\x01\n" +
+	"\tUserEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x123\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x1f.wallet.events.v1.UserEventTypeR\x04type\x12%\n" +
+	"\x0eschema_version\x18\x03 \x01(\rR\rschemaVersion\x12;\n" +
+	"\voccurred_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x120\n" +
+	"\apayload\x18\x05 \x01(\v2\x16.wallet.events.v1.UserR\apayload\"\x8c\x01\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"blocked_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tblockedAt\x129\n" +
+	"\n" +
+	"deleted_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\"\xab\x02\n" +
+	"\vWalletEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x125\n" +
+	"\x04type\x18\x02 \x01(\x0e2!.wallet.events.v1.WalletEventTypeR\x04type\x12\x1b\n" +
+	"\twallet_id\x18\x03 \x01(\tR\bwalletId\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\tR\x06userId\x12%\n" +
+	"\x0etransaction_id\x18\x05 \x01(\tR\rtransactionId\x12;\n" +
+	"\voccurred_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x120\n" +
+	"\x06wallet\x18\a \x01(\v2\x18.wallet.events.v1.WalletR\x06wallet\"\xfe\x03\n" +
+	"\x06Wallet\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x121\n" +
+	"\abalance\x18\x04 \x01(\v2\x17.wallet.events.v1.MoneyR\abalance\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12L\n" +
+	"\x14closure_requested_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x12closureRequestedAt\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x129\n" +
+	"\n" +
+	"deleted_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x12 \n" +
+	"\vdescription\x18\n" +
+	" \x01(\tR\vdescription\x12\x12\n" +
+	"\x04tags\x18\v \x03(\tR\x04tags\x12\x1d\n" +
+	"\n" +
+	"is_default\x18\f \x01(\bR\tisDefault\x12\x18\n" +
+	"\aversion\x18\r \x01(\x03R\aversion\";\n" +
+	"\x05Money\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency*\xa6\x01\n" +
+	"\rUserEventType\x12\x1f\n" +
+	"\x1bUSER_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17USER_EVENT_TYPE_CREATED\x10\x01\x12\x1b\n" +
+	"\x17USER_EVENT_TYPE_BLOCKED\x10\x02\x12\x1d\n" +
+	"\x19USER_EVENT_TYPE_UNBLOCKED\x10\x03\x12\x1b\n" +
+	"\x17USER_EVENT_TYPE_DELETED\x10\x04*\xf5\x01\n" +
+	"\x0fWalletEventType\x12!\n" +
+	"\x1dWALLET_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19WALLET_EVENT_TYPE_CREATED\x10\x01\x12\x1d\n" +
+	"\x19WALLET_EVENT_TYPE_RENAMED\x10\x02\x12\x1d\n" +
+	"\x19WALLET_EVENT_TYPE_DELETED\x10\x03\x12%\n" +
+	"!WALLET_EVENT_TYPE_BALANCE_CHANGED\x10\x04\x12\x1c\n" +
+	"\x18WALLET_EVENT_TYPE_FROZEN\x10\x05\x12\x1d\n" +
+	"\x19WALLET_EVENT_TYPE_UPDATED\x10\x06BA\n" +
+	"\x14com.wallet.events.v1P\x01Z'wallet-service/internal/events/eventspbb\x06proto3"
+
+var (
+	file_events_proto_rawDescOnce sync.Once
+	file_events_proto_rawDescData []byte
+)
+
+func file_events_proto_rawDescGZIP() []byte {
+	file_events_proto_rawDescOnce.Do(func() {
+		file_events_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_events_proto_rawDesc), len(file_events_proto_rawDesc)))
+	})
+	return file_events_proto_rawDescData
+}
+
+var file_events_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_events_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_events_proto_goTypes = []any{
+	(UserEventType)(0),            // 0: wallet.events.v1.UserEventType
+	(WalletEventType)(0),          // 1: wallet.events.v1.WalletEventType
+	(*UserEvent)(nil),             // 2: wallet.events.v1.UserEvent
+	(*User)(nil),                  // 3: wallet.events.v1.User
+	(*WalletEvent)(nil),           // 4: wallet.events.v1.WalletEvent
+	(*Wallet)(nil),                // 5: wallet.events.v1.Wallet
+	(*Money)(nil),                 // 6: wallet.events.v1.Money
+	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
+}
+var file_events_proto_depIdxs = []int32{
+	0,  // 0: wallet.events.v1.UserEvent.type:type_name -> wallet.events.v1.UserEventType
+	7,  // 1: wallet.events.v1.UserEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	3,  // 2: wallet.events.v1.UserEvent.payload:type_name -> wallet.events.v1.User
+	7,  // 3: wallet.events.v1.User.blocked_at:type_name -> google.protobuf.Timestamp
+	7,  // 4: wallet.events.v1.User.deleted_at:type_name -> google.protobuf.Timestamp
+	1,  // 5: wallet.events.v1.WalletEvent.type:type_name -> wallet.events.v1.WalletEventType
+	7,  // 6: wallet.events.v1.WalletEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	5,  // 7: wallet.events.v1.WalletEvent.wallet:type_name -> wallet.events.v1.Wallet
+	6,  // 8: wallet.events.v1.Wallet.balance:type_name -> wallet.events.v1.Money
+	7,  // 9: wallet.events.v1.Wallet.closure_requested_at:type_name -> google.protobuf.Timestamp
+	7,  // 10: wallet.events.v1.Wallet.created_at:type_name -> google.protobuf.Timestamp
+	7,  // 11: wallet.events.v1.Wallet.updated_at:type_name -> google.protobuf.Timestamp
+	7,  // 12: wallet.events.v1.Wallet.deleted_at:type_name -> google.protobuf.Timestamp
+	13, // [13:13] is the sub-list for method output_type
+	13, // [13:13] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_events_proto_init() }
+func file_events_proto_init() {
+	if File_events_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_events_proto_rawDesc), len(file_events_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_events_proto_goTypes,
+		DependencyIndexes: file_events_proto_depIdxs,
+		EnumInfos:         file_events_proto_enumTypes,
+		MessageInfos:      file_events_proto_msgTypes,
+	}.Build()
+	File_events_proto = out.File
+	file_events_proto_goTypes = nil
+	file_events_proto_depIdxs = nil
+}
@@ -0,0 +1,181 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/events/eventspb"
+)
+
+var userEventTypes = map[UserEventType]eventspb.UserEventType{
+	UserCreated:   eventspb.UserEventType_USER_EVENT_TYPE_CREATED,
+	UserBlocked:   eventspb.UserEventType_USER_EVENT_TYPE_BLOCKED,
+	UserUnblocked: eventspb.UserEventType_USER_EVENT_TYPE_UNBLOCKED,
+	UserDeleted:   eventspb.UserEventType_USER_EVENT_TYPE_DELETED,
+}
+
+var walletEventTypes = map[domain.WalletEventType]eventspb.WalletEventType{
+	domain.WalletCreated:        eventspb.WalletEventType_WALLET_EVENT_TYPE_CREATED,
+	domain.WalletUpdated:        eventspb.WalletEventType_WALLET_EVENT_TYPE_UPDATED,
+	domain.WalletRenamed:        eventspb.WalletEventType_WALLET_EVENT_TYPE_RENAMED,
+	domain.WalletDeleted:        eventspb.WalletEventType_WALLET_EVENT_TYPE_DELETED,
+	domain.WalletBalanceChanged: eventspb.WalletEventType_WALLET_EVENT_TYPE_BALANCE_CHANGED,
+	domain.WalletFrozenEvent:    eventspb.WalletEventType_WALLET_EVENT_TYPE_FROZEN,
+}
+
+func userEventToProto(event UserEvent) *eventspb.UserEvent {
+	return &eventspb.UserEvent{
+		EventId:       event.EventId.String(),
+		Type:          userEventTypes[event.Type],
+		SchemaVersion: uint32(event.SchemaVersion),
+		OccurredAt:    timestamppb.New(event.OccurredAt),
+		Payload: &eventspb.User{
+			Id:        event.Payload.Id.String(),
+			BlockedAt: toTimestamp(event.Payload.BlockedAt),
+			DeletedAt: toTimestamp(event.Payload.DeletedAt),
+		},
+	}
+}
+
+func userEventFromProto(msg *eventspb.UserEvent) (UserEvent, error) {
+	eventId, err := uuid.Parse(msg.GetEventId())
+	if err != nil {
+		return UserEvent{}, fmt.Errorf("%w: event id: %w", ErrInvalidEvent, err)
+	}
+
+	userId, err := uuid.Parse(msg.GetPayload().GetId())
+	if err != nil {
+		return UserEvent{}, fmt.Errorf("%w: user id: %w", ErrInvalidEvent, err)
+	}
+
+	// A type this build does not know keeps its proto name, so that Validate
+	// reports it.
+	event := UserEvent{
+		EventId:       eventId,
+		Type:          UserEventType(msg.GetType().String()),
+		SchemaVersion: int(msg.GetSchemaVersion()),
+		OccurredAt:    msg.GetOccurredAt().AsTime(),
+		Payload: UserPayload{
+			Id:        userId,
+			BlockedAt: fromTimestamp(msg.GetPayload().GetBlockedAt()),
+			DeletedAt: fromTimestamp(msg.GetPayload().GetDeletedAt()),
+		},
+	}
+
+	for eventType, protoType := range userEventTypes {
+		if protoType == msg.GetType() {
+			event.Type = eventType
+		}
+	}
+
+	return event, nil
+}
+
+func walletEventToProto(event domain.WalletEvent) *eventspb.WalletEvent {
+	msg := &eventspb.WalletEvent{
+		EventId:    event.Id.String(),
+		Type:       walletEventTypes[event.Type],
+		WalletId:   event.WalletId.String(),
+		UserId:     event.UserId,
+		OccurredAt: timestamppb.New(event.OccurredAt),
+		Wallet: &eventspb.Wallet{
+			Id:          event.Wallet.Id.String(),
+			UserId:      event.UserId,
+			Name:        event.Wallet.Name,
+			Description: event.Wallet.Description,
+			Tags:        event.Wallet.Tags,
+			IsDefault:   event.Wallet.IsDefault,
+			Balance: &eventspb.Money{
+				Amount:   event.Wallet.Balance.Amount,
+				Currency: event.Wallet.Balance.Currency,
+			},
+			Status:             string(event.Wallet.Status),
+			Version:            event.Wallet.Version,
+			ClosureRequestedAt: toTimestamp(event.Wallet.ClosureRequestedAt),
+			CreatedAt:          timestamppb.New(event.Wallet.CreatedAt),
+			UpdatedAt:          timestamppb.New(event.Wallet.UpdatedAt),
+			DeletedAt:          toTimestamp(event.Wallet.DeletedAt),
+		},
+	}
+
+	if event.TransactionId != nil {
+		msg.TransactionId = event.TransactionId.String()
+	}
+
+	return msg
+}
+
+func walletEventFromProto(msg *eventspb.WalletEvent) (domain.WalletEvent, error) {
+	eventId, err := uuid.Parse(msg.GetEventId())
+	if err != nil {
+		return domain.WalletEvent{}, fmt.Errorf("failed to decode the wallet event id: %w", err)
+	}
+
+	walletId, err := uuid.Parse(msg.GetWalletId())
+	if err != nil {
+		return domain.WalletEvent{}, fmt.Errorf("failed to decode the wallet id: %w", err)
+	}
+
+	wallet := msg.GetWallet()
+
+	event := domain.WalletEvent{
+		Id:         eventId,
+		WalletId:   walletId,
+		UserId:     msg.GetUserId(),
+		OccurredAt: msg.GetOccurredAt().AsTime(),
+		Wallet: domain.Wallet{
+			Id:                 walletId,
+			UserId:             msg.GetUserId(),
+			Name:               wallet.GetName(),
+			Description:        wallet.GetDescription(),
+			Tags:               append([]string{}, wallet.GetTags()...),
+			IsDefault:          wallet.GetIsDefault(),
+			Balance:            domain.NewMoney(wallet.GetBalance().GetAmount(), wallet.GetBalance().GetCurrency()),
+			Currency:           wallet.GetBalance().GetCurrency(),
+			Status:             domain.WalletStatus(wallet.GetStatus()),
+			ClosureRequestedAt: fromTimestamp(wallet.GetClosureRequestedAt()),
+			Version:            wallet.GetVersion(),
+			CreatedAt:          wallet.GetCreatedAt().AsTime(),
+			UpdatedAt:          wallet.GetUpdatedAt().AsTime(),
+			DeletedAt:          fromTimestamp(wallet.GetDeletedAt()),
+		},
+	}
+
+	for eventType, protoType := range walletEventTypes {
+		if protoType == msg.GetType() {
+			event.Type = eventType
+		}
+	}
+
+	if msg.GetTransactionId() != "" {
+		transactionId, err := uuid.Parse(msg.GetTransactionId())
+		if err != nil {
+			return domain.WalletEvent{}, fmt.Errorf("failed to decode the transaction id: %w", err)
+		}
+
+		event.TransactionId = &transactionId
+	}
+
+	return event, nil
+}
+
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+
+	t := ts.AsTime()
+
+	return &t
+}
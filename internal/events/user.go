@@ -0,0 +1,102 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"wallet-service/internal/domain"
+)
+
+// UserSchemaVersion is the version of the user event written by this build.
+// Readers reject any other version instead of guessing at its layout.
+const UserSchemaVersion = 1
+
+var (
+	ErrUnsupportedSchemaVersion = errors.New("unsupported user event schema version")
+	ErrUnknownEventType         = errors.New("unknown user event type")
+	ErrInvalidEvent             = errors.New("invalid user event")
+)
+
+type UserEventType string
+
+const (
+	UserCreated   UserEventType = "user.created"
+	UserBlocked   UserEventType = "user.blocked"
+	UserUnblocked UserEventType = "user.unblocked"
+	UserDeleted   UserEventType = "user.deleted"
+)
+
+func (t UserEventType) Valid() bool {
+	switch t {
+	case UserCreated, UserBlocked, UserUnblocked, UserDeleted:
+		return true
+	default:
+		return false
+	}
+}
+
+// UserEvent is the envelope of every message on the users topic. The payload
+// carries the full user state after the event, so applying the latest event of
+// a user is enough to bring its row up to date.
+type UserEvent struct {
+	EventId       uuid.UUID     `json:"eventId"`
+	Type          UserEventType `json:"type"`
+	SchemaVersion int           `json:"schemaVersion"`
+	OccurredAt    time.Time     `json:"occurredAt"`
+	Payload       UserPayload   `json:"payload"`
+}
+
+type UserPayload struct {
+	Id        uuid.UUID  `json:"id"`
+	BlockedAt *time.Time `json:"blockedAt"`
+	DeletedAt *time.Time `json:"deletedAt"`
+}
+
+func NewUserEvent(eventType UserEventType, user domain.User) UserEvent {
+	return UserEvent{
+		EventId:       uuid.New(),
+		Type:          eventType,
+		SchemaVersion: UserSchemaVersion,
+		OccurredAt:    time.Now().UTC(),
+		Payload: UserPayload{
+			Id:        user.Id,
+			BlockedAt: user.BlockedAt,
+			DeletedAt: user.DeletedAt,
+		},
+	}
+}
+
+func (e UserEvent) User() domain.User {
+	return domain.User{
+		Id:        e.Payload.Id,
+		BlockedAt: e.Payload.BlockedAt,
+		DeletedAt: e.Payload.DeletedAt,
+	}
+}
+
+// Validate checks that the event is complete and that its payload agrees with
+// its type.
+func (e UserEvent) Validate() error {
+	if !e.Type.Valid() {
+		return fmt.Errorf("%w: %q", ErrUnknownEventType, e.Type)
+	}
+
+	switch {
+	case e.EventId == uuid.Nil:
+		return fmt.Errorf("%w: event id is missing", ErrInvalidEvent)
+	case e.Payload.Id == uuid.Nil:
+		return fmt.Errorf("%w: user id is missing", ErrInvalidEvent)
+	case e.OccurredAt.IsZero():
+		return fmt.Errorf("%w: occurredAt is missing", ErrInvalidEvent)
+	case e.Type == UserBlocked && e.Payload.BlockedAt == nil:
+		return fmt.Errorf("%w: blocked user without blockedAt", ErrInvalidEvent)
+	case e.Type == UserUnblocked && e.Payload.BlockedAt != nil:
+		return fmt.Errorf("%w: unblocked user with blockedAt", ErrInvalidEvent)
+	case e.Type == UserDeleted && e.Payload.DeletedAt == nil:
+		return fmt.Errorf("%w: deleted user without deletedAt", ErrInvalidEvent)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"wallet-service/internal/events"
+	"wallet-service/internal/transport/kafka/producer"
+)
+
+type userEventProducer interface {
+	Produce(ctx context.Context, event any, opts ...producer.MessageOption) error
+}
+
+// Load describes how many events to send and how fast. A zero Rate sends as
+// fast as the workers can; a zero Count or Duration does not limit the run.
+type Load struct {
+	Rate     float64
+	Count    int
+	Duration time.Duration
+	Workers  int
+}
+
+type Report struct {
+	Sent     int64
+	Errors   int64
+	ByType   map[events.UserEventType]int64
+	Elapsed  time.Duration
+	Achieved float64
+}
+
+// Run produces events from the generator until ctx is cancelled or the load
+// is complete. Events of a user are always sent by the same worker, so they
+// reach Kafka in the order they were generated.
+func Run(ctx context.Context, p userEventProducer, users *UserGenerator, load Load) Report {
+	if load.Duration > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, load.Duration)
+		defer cancel()
+	}
+
+	workers := max(load.Workers, 1)
+	queues := make([]chan events.UserEvent, workers)
+
+	var (
+		wg     sync.WaitGroup
+		sent   atomic.Int64
+		failed atomic.Int64
+		mu     sync.Mutex
+	)
+
+	byType := make(map[events.UserEventType]int64)
+
+	for i := range queues {
+		queues[i] = make(chan events.UserEvent, 64)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for event := range queues[i] {
+				// Once the run is over the queued events are dropped, but the
+				// ones being sent are allowed to finish.
+				if ctx.Err() != nil {
+					continue
+				}
+
+				err := p.Produce(context.WithoutCancel(ctx), event,
+					producer.WithKey(event.Payload.Id.String()),
+					producer.WithEventType(string(event.Type)),
+					producer.WithSchemaVersion(event.SchemaVersion),
+					producer.WithCorrelationId(event.EventId.String()))
+				if err != nil {
+					logrus.Errorf("Producer error: %v", err)
+
+					failed.Add(1)
+
+					continue
+				}
+
+				sent.Add(1)
+
+				mu.Lock()
+				byType[event.Type]++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	start := time.Now()
+
+	for n := 0; load.Count == 0 || n < load.Count; n++ {
+		if load.Rate > 0 {
+			next := start.Add(time.Duration(float64(n) / load.Rate * float64(time.Second)))
+
+			select {
+			case <-time.After(time.Until(next)):
+			case <-ctx.Done():
+			}
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		event := users.Next()
+
+		hash := fnv.New32a()
+		_, _ = hash.Write(event.Payload.Id[:])
+
+		select {
+		case queues[hash.Sum32()%uint32(workers)] <- event:
+		case <-ctx.Done():
+		}
+	}
+
+	for _, queue := range queues {
+		close(queue)
+	}
+
+	wg.Wait()
+
+	report := Report{
+		Sent:    sent.Load(),
+		Errors:  failed.Load(),
+		ByType:  byType,
+		Elapsed: time.Since(start),
+	}
+
+	if report.Elapsed > 0 {
+		report.Achieved = float64(report.Sent) / report.Elapsed.Seconds()
+	}
+
+	return report
+}
@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"wallet-service/internal/events"
+	"wallet-service/internal/transport/kafka/producer"
+)
+
+type fakeProducer struct {
+	mu       sync.Mutex
+	messages []producer.Message
+}
+
+func (p *fakeProducer) Produce(_ context.Context, event any, opts ...producer.MessageOption) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.messages = append(p.messages, producer.NewMessage(event, opts...))
+
+	return nil
+}
+
+func TestRunSetsMessageHeaders(t *testing.T) {
+	p := &fakeProducer{}
+
+	report := Run(context.Background(), p, NewUserGenerator(1, DefaultMix(), 10), Load{Count: 50, Workers: 3})
+	require.Equal(t, int64(50), report.Sent)
+	require.Len(t, p.messages, 50)
+
+	for _, msg := range p.messages {
+		event, ok := msg.Event.(events.UserEvent)
+		require.True(t, ok)
+
+		headers := make(map[string]string)
+		for _, header := range msg.Headers {
+			headers[header.Key] = string(header.Value)
+		}
+
+		require.Equal(t, event.Payload.Id.String(), msg.Key)
+		require.Equal(t, string(event.Type), headers[events.HeaderEventType])
+		require.Equal(t, "1", headers[events.HeaderSchemaVersion])
+		require.Equal(t, event.EventId.String(), headers[events.HeaderCorrelationId])
+	}
+}
@@ -1,29 +1,185 @@
 package generator
 
 import (
-	"encoding/json"
-	"fmt"
+	"encoding/binary"
+	"math/rand/v2"
+	"time"
 
-	"github.com/brianvoe/gofakeit"
+	"github.com/google/uuid"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/events"
 )
 
+// Mix weighs how often each event type is generated. Weights are relative: a
+// mix of {Create: 3, Block: 1} creates three users for every block.
+type Mix struct {
+	Create  int `json:"create"`
+	Block   int `json:"block"`
+	Unblock int `json:"unblock"`
+	Delete  int `json:"delete"`
+}
+
+func DefaultMix() Mix {
+	return Mix{
+		Create:  4,
+		Block:   3,
+		Unblock: 2,
+		Delete:  1,
+	}
+}
+
+// UserGenerator produces a plausible stream of user events: it keeps a pool of
+// existing users and only blocks, unblocks or deletes users from the pool. The
+// same seed produces the same sequence of events and ids.
 type UserGenerator struct {
-	Name   string `json:"name"`
-	Age    int64  `json:"age"`
-	Gender string `json:"gender"`
+	rnd      *rand.Rand
+	ids      *rand.ChaCha8
+	mix      Mix
+	poolSize int
+	users    []domain.User
+}
+
+func NewUserGenerator(seed uint64, mix Mix, poolSize int) *UserGenerator {
+	var chachaSeed [32]byte
+	binary.LittleEndian.PutUint64(chachaSeed[:], seed)
+
+	return &UserGenerator{
+		rnd:      rand.New(rand.NewPCG(seed, seed)),
+		ids:      rand.NewChaCha8(chachaSeed),
+		mix:      mix,
+		poolSize: max(poolSize, 1),
+	}
+}
+
+// AddUsers puts existing users, e.g. loaded from the database, into the pool.
+func (g *UserGenerator) AddUsers(users ...domain.User) {
+	for _, user := range users {
+		g.addUser(user)
+	}
+}
+
+// Next generates the next event. Blocks go to users that are not blocked and
+// unblocks to users that are; when no user qualifies a new user is created
+// instead.
+func (g *UserGenerator) Next() events.UserEvent {
+	eventType := g.pickType()
+
+	i, ok := g.pickUser(eventType)
+	if !ok {
+		user := domain.User{
+			Id: g.newId(),
+		}
+
+		g.addUser(user)
+
+		return g.newEvent(events.UserCreated, user)
+	}
+
+	user := &g.users[i]
+	now := time.Now().UTC()
+
+	switch eventType {
+	case events.UserBlocked:
+		user.BlockedAt = &now
+	case events.UserUnblocked:
+		user.BlockedAt = nil
+	case events.UserDeleted:
+		user.DeletedAt = &now
+	}
+
+	event := g.newEvent(eventType, *user)
+
+	if eventType == events.UserDeleted {
+		g.users = append(g.users[:i], g.users[i+1:]...)
+	}
+
+	return event
 }
 
-func GenerateUser() ([]byte, error) {
-	fakeUser := &UserGenerator{
-		Name:   gofakeit.BeerName(),
-		Age:    gofakeit.Int64(),
-		Gender: gofakeit.Gender(),
+// pickUser picks a random user of the pool that the event can apply to.
+func (g *UserGenerator) pickUser(eventType events.UserEventType) (int, bool) {
+	var candidates []int
+
+	for i, user := range g.users {
+		switch eventType {
+		case events.UserBlocked:
+			if user.BlockedAt == nil {
+				candidates = append(candidates, i)
+			}
+		case events.UserUnblocked:
+			if user.BlockedAt != nil {
+				candidates = append(candidates, i)
+			}
+		case events.UserDeleted:
+			candidates = append(candidates, i)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return 0, false
+	}
+
+	return candidates[g.rnd.IntN(len(candidates))], true
+}
+
+func (g *UserGenerator) pickType() events.UserEventType {
+	weights := []struct {
+		eventType events.UserEventType
+		weight    int
+	}{
+		{events.UserCreated, g.mix.Create},
+		{events.UserBlocked, g.mix.Block},
+		{events.UserUnblocked, g.mix.Unblock},
+		{events.UserDeleted, g.mix.Delete},
 	}
 
-	fakeUserBytes, err := json.Marshal(fakeUser)
+	total := 0
+	for _, w := range weights {
+		total += max(w.weight, 0)
+	}
+
+	if total == 0 {
+		return events.UserCreated
+	}
+
+	pick := g.rnd.IntN(total)
+
+	for _, w := range weights {
+		if pick < max(w.weight, 0) {
+			return w.eventType
+		}
+
+		pick -= max(w.weight, 0)
+	}
+
+	return events.UserCreated
+}
+
+// addUser adds the user to the pool, evicting a random user once the pool is
+// full. Evicted users still exist, they just receive no more events.
+func (g *UserGenerator) addUser(user domain.User) {
+	if len(g.users) < g.poolSize {
+		g.users = append(g.users, user)
+
+		return
+	}
+
+	g.users[g.rnd.IntN(len(g.users))] = user
+}
+
+func (g *UserGenerator) newEvent(eventType events.UserEventType, user domain.User) events.UserEvent {
+	event := events.NewUserEvent(eventType, user)
+	event.EventId = g.newId()
+
+	return event
+}
+
+func (g *UserGenerator) newId() uuid.UUID {
+	id, err := uuid.NewRandomFromReader(g.ids)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal UserGenerator: %w", err)
+		// ChaCha8 never fails to read.
+		panic(err)
 	}
 
-	return fakeUserBytes, nil
+	return id
 }
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"wallet-service/internal/events"
+)
+
+func TestUserGeneratorMix(t *testing.T) {
+	const total = 20000
+
+	tests := []struct {
+		name string
+		mix  Mix
+	}{
+		{name: "default", mix: DefaultMix()},
+		{name: "blocks heavy", mix: Mix{Create: 2, Block: 5, Unblock: 3}},
+		{name: "creates only", mix: Mix{Create: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewUserGenerator(42, tt.mix, 1000)
+
+			counts := make(map[events.UserEventType]int)
+			blocked := make(map[uuid.UUID]bool)
+
+			for range total {
+				event := generator.Next()
+				require.NoError(t, event.Validate())
+
+				counts[event.Type]++
+
+				// Blocks and unblocks always change the state of the user.
+				switch event.Type {
+				case events.UserBlocked:
+					require.False(t, blocked[event.Payload.Id], "blocked user %s blocked again", event.Payload.Id)
+					blocked[event.Payload.Id] = true
+				case events.UserUnblocked:
+					require.True(t, blocked[event.Payload.Id], "unblocked user %s was not blocked", event.Payload.Id)
+					blocked[event.Payload.Id] = false
+				}
+			}
+
+			weights := map[events.UserEventType]int{
+				events.UserCreated:   tt.mix.Create,
+				events.UserBlocked:   tt.mix.Block,
+				events.UserUnblocked: tt.mix.Unblock,
+				events.UserDeleted:   tt.mix.Delete,
+			}
+
+			sum := tt.mix.Create + tt.mix.Block + tt.mix.Unblock + tt.mix.Delete
+
+			for eventType, weight := range weights {
+				want := float64(weight) / float64(sum)
+				got := float64(counts[eventType]) / total
+
+				require.InDelta(t, want, got, 0.02, "share of %s", eventType)
+			}
+		})
+	}
+}
+
+func TestUserGeneratorSeed(t *testing.T) {
+	first := NewUserGenerator(7, DefaultMix(), 10)
+	second := NewUserGenerator(7, DefaultMix(), 10)
+	other := NewUserGenerator(8, DefaultMix(), 10)
+
+	differs := false
+
+	for range 1000 {
+		a, b, c := first.Next(), second.Next(), other.Next()
+
+		require.Equal(t, a.EventId, b.EventId)
+		require.Equal(t, a.Type, b.Type)
+		require.Equal(t, a.Payload.Id, b.Payload.Id)
+
+		differs = differs || a.EventId != c.EventId
+	}
+
+	require.True(t, differs, "another seed must produce other events")
+}
@@ -0,0 +1,150 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	configs "wallet-service/internal/config"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/events"
+)
+
+type store interface {
+	RelayPending(ctx context.Context, limit int,
+		publish func(ctx context.Context, messages []domain.OutboxMessage) error) (int, error)
+	DeleteSent(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+type publisher interface {
+	ProduceMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Relay moves wallet events from the outbox to Kafka. Events are keyed by
+// wallet id, so the events of one wallet keep their order on the topic. A
+// batch that fails to publish stays in the outbox and is sent again, which
+// makes delivery at-least-once: consumers must tolerate duplicates. Sent
+// messages are purged once they are older than the retention.
+type Relay struct {
+	store         store
+	publisher     publisher
+	batchSize     int
+	pollInterval  time.Duration
+	contentType   string
+	retention     time.Duration
+	purgeInterval time.Duration
+}
+
+func NewRelay(cfg *configs.Config, store store, publisher publisher) *Relay {
+	return &Relay{
+		store:         store,
+		publisher:     publisher,
+		batchSize:     cfg.Outbox.BatchSize,
+		pollInterval:  cfg.Outbox.PollInterval,
+		contentType:   cfg.Outbox.ContentType,
+		retention:     cfg.Outbox.Retention,
+		purgeInterval: cfg.Outbox.PurgeInterval,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) error {
+	logrus.Info("Relaying outbox messages...")
+
+	var lastPurge time.Time
+
+	for {
+		if r.retention > 0 && time.Since(lastPurge) >= r.purgeInterval {
+			r.purge(ctx)
+
+			lastPurge = time.Now()
+		}
+
+		relayed, err := r.store.RelayPending(ctx, r.batchSize, r.publish)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			logrus.Errorf("failed to relay outbox messages: %v", err)
+		}
+
+		// A full batch means more messages are likely waiting.
+		if relayed == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-time.After(r.pollInterval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (r *Relay) purge(ctx context.Context) {
+	deleted, err := r.store.DeleteSent(ctx, r.retention)
+	if err != nil {
+		if ctx.Err() == nil {
+			logrus.Errorf("failed to purge sent outbox messages: %v", err)
+		}
+
+		return
+	}
+
+	logrus.Infof("Purged %d sent outbox messages", deleted)
+}
+
+func (r *Relay) publish(ctx context.Context, messages []domain.OutboxMessage) error {
+	msgs := make([]kafka.Message, 0, len(messages))
+
+	for _, message := range messages {
+		event, err := events.DecodeWalletEvent(message.Payload, events.ContentTypeJSON)
+		if err != nil {
+			return err
+		}
+
+		value, err := r.encode(message.Payload, event)
+		if err != nil {
+			return err
+		}
+
+		msgs = append(msgs, kafka.Message{
+			Key:   []byte(message.AggregateId.String()),
+			Value: value,
+			Time:  message.CreatedAt,
+			Headers: []kafka.Header{
+				{Key: events.HeaderEventType, Value: []byte(message.EventType)},
+				{Key: events.HeaderContentType, Value: []byte(r.contentType)},
+				{Key: events.HeaderCorrelationId, Value: []byte(correlationId(event))},
+			},
+		})
+	}
+
+	if err := r.publisher.ProduceMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("failed to publish outbox messages: %w", err)
+	}
+
+	return nil
+}
+
+// correlationId ties together the events of one ledger transaction, such as
+// both sides of a transfer; any other event correlates only with itself.
+func correlationId(event domain.WalletEvent) string {
+	if event.TransactionId != nil {
+		return event.TransactionId.String()
+	}
+
+	return event.Id.String()
+}
+
+// encode re-encodes the JSON payload stored in the outbox with the configured
+// codec.
+func (r *Relay) encode(payload []byte, event domain.WalletEvent) ([]byte, error) {
+	if r.contentType == events.ContentTypeJSON {
+		return payload, nil
+	}
+
+	return events.Encode(event, r.contentType)
+}
@@ -0,0 +1,146 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/events"
+)
+
+type fakePublisher struct {
+	messages []kafka.Message
+}
+
+func (p *fakePublisher) ProduceMessages(_ context.Context, msgs ...kafka.Message) error {
+	p.messages = append(p.messages, msgs...)
+
+	return nil
+}
+
+// fakeStore has nothing pending and stops the relay after stopAfter polls.
+type fakeStore struct {
+	stopAfter  int
+	cancel     context.CancelFunc
+	polls      int
+	retentions []time.Duration
+}
+
+func (s *fakeStore) RelayPending(context.Context, int,
+	func(ctx context.Context, messages []domain.OutboxMessage) error,
+) (int, error) {
+	s.polls++
+	if s.polls == s.stopAfter {
+		s.cancel()
+	}
+
+	return 0, nil
+}
+
+func (s *fakeStore) DeleteSent(_ context.Context, retention time.Duration) (int64, error) {
+	s.retentions = append(s.retentions, retention)
+
+	return 0, nil
+}
+
+func outboxMessage(t *testing.T, event domain.WalletEvent) domain.OutboxMessage {
+	t.Helper()
+
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	return domain.OutboxMessage{AggregateId: event.WalletId, EventType: event.Type, Payload: payload}
+}
+
+func header(msg kafka.Message, key string) string {
+	for _, header := range msg.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+
+	return ""
+}
+
+func wallet() domain.Wallet {
+	return domain.Wallet{
+		Id:       uuid.New(),
+		UserId:   uuid.NewString(),
+		Name:     "wallet",
+		Balance:  domain.NewMoney(100, "USD"),
+		Currency: "USD",
+		Status:   domain.WalletActive,
+	}
+}
+
+func TestRelaySetsCorrelationId(t *testing.T) {
+	transactionId := uuid.New()
+
+	from := domain.NewWalletEvent(domain.WalletBalanceChanged, wallet())
+	from.TransactionId = &transactionId
+
+	to := domain.NewWalletEvent(domain.WalletBalanceChanged, wallet())
+	to.TransactionId = &transactionId
+
+	created := domain.NewWalletEvent(domain.WalletCreated, wallet())
+
+	for _, contentType := range []string{events.ContentTypeJSON, events.ContentTypeProtobuf} {
+		t.Run(contentType, func(t *testing.T) {
+			publisher := &fakePublisher{}
+			relay := &Relay{publisher: publisher, contentType: contentType}
+
+			err := relay.publish(context.Background(), []domain.OutboxMessage{
+				outboxMessage(t, from), outboxMessage(t, to), outboxMessage(t, created),
+			})
+			require.NoError(t, err)
+			require.Len(t, publisher.messages, 3)
+
+			// Both sides of a transfer share the transaction id.
+			require.Equal(t, transactionId.String(), header(publisher.messages[0], events.HeaderCorrelationId))
+			require.Equal(t, transactionId.String(), header(publisher.messages[1], events.HeaderCorrelationId))
+			require.Equal(t, created.Id.String(), header(publisher.messages[2], events.HeaderCorrelationId))
+
+			for _, msg := range publisher.messages {
+				require.Equal(t, contentType, header(msg, events.HeaderContentType))
+			}
+		})
+	}
+}
+
+func TestRelayPurgesSentMessages(t *testing.T) {
+	tests := []struct {
+		name          string
+		retention     time.Duration
+		purgeInterval time.Duration
+		want          []time.Duration
+	}{
+		{name: "once per interval", retention: time.Hour, purgeInterval: time.Hour, want: []time.Duration{time.Hour}},
+		{name: "on every poll", retention: time.Hour, want: []time.Duration{time.Hour, time.Hour, time.Hour}},
+		{name: "disabled", purgeInterval: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			store := &fakeStore{stopAfter: 3, cancel: cancel}
+			relay := &Relay{
+				store:         store,
+				batchSize:     10,
+				pollInterval:  time.Millisecond,
+				retention:     tt.retention,
+				purgeInterval: tt.purgeInterval,
+			}
+
+			require.NoError(t, relay.Run(ctx))
+			require.Equal(t, 3, store.polls)
+			require.Equal(t, tt.want, store.retentions)
+		})
+	}
+}
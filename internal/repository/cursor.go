@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"wallet-service/internal/domain"
+)
+
+// Cursors are opaque to clients: the keyset position is serialized as JSON and
+// encoded with URL-safe base64 so it can be passed back in a query string.
+func encodeCursor(position any) (string, error) {
+	data, err := json.Marshal(position)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string, position any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidCursor, err)
+	}
+
+	if err := json.Unmarshal(data, position); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidCursor, err)
+	}
+
+	return nil
+}
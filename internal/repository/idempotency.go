@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"wallet-service/internal/domain"
+)
+
+type IdempotencyRepository struct {
+	db              *sqlx.DB
+	inFlightTimeout time.Duration
+}
+
+func NewIdempotencyRepository(db *sqlx.DB, inFlightTimeout time.Duration) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		db:              db,
+		inFlightTimeout: inFlightTimeout,
+	}
+}
+
+// Reserve claims the key for a new request. It returns reserved == true when
+// the caller must execute the request, otherwise the stored response of the
+// first request. An expired key, or one left unfinished by a crashed request
+// for longer than inFlightTimeout, is claimed again as if it never existed.
+func (i *IdempotencyRepository) Reserve(ctx context.Context, userId uuid.UUID, key, requestHash string,
+	ttl time.Duration,
+) (domain.IdempotentResponse, bool, error) {
+	reserveQuery := `INSERT INTO idempotency_keys (user_id, key, request_hash, expires_at)
+	VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+	ON CONFLICT (user_id, key) DO UPDATE SET
+		request_hash = excluded.request_hash,
+		status_code = NULL,
+		response_headers = NULL,
+		response_body = NULL,
+		created_at = NOW(),
+		expires_at = excluded.expires_at
+	WHERE idempotency_keys.expires_at < NOW()
+	OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < NOW() - make_interval(secs => $5))`
+
+	result, err := i.db.ExecContext(ctx, reserveQuery, userId, key, requestHash, ttl.Seconds(), i.inFlightTimeout.Seconds())
+	if err != nil {
+		return domain.IdempotentResponse{}, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return domain.IdempotentResponse{}, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	if affected == 1 {
+		return domain.IdempotentResponse{}, true, nil
+	}
+
+	var (
+		storedHash string
+		statusCode sql.NullInt64
+		headers    []byte
+		body       []byte
+	)
+
+	selectQuery := `SELECT request_hash, status_code, response_headers, response_body
+	FROM idempotency_keys
+	WHERE user_id = $1 AND key = $2`
+
+	if err := i.db.QueryRowContext(ctx, selectQuery, userId, key).Scan(&storedHash, &statusCode, &headers, &body); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.IdempotentResponse{}, false, domain.ErrIdempotencyKeyInFlight
+		}
+
+		return domain.IdempotentResponse{}, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	if storedHash != requestHash {
+		return domain.IdempotentResponse{}, false, domain.ErrIdempotencyKeyReused
+	}
+
+	if !statusCode.Valid {
+		return domain.IdempotentResponse{}, false, domain.ErrIdempotencyKeyInFlight
+	}
+
+	stored := domain.IdempotentResponse{
+		StatusCode: int(statusCode.Int64),
+		Body:       body,
+	}
+
+	if err := json.Unmarshal(headers, &stored.Headers); err != nil {
+		return domain.IdempotentResponse{}, false, fmt.Errorf("failed to unmarshal stored headers: %w", err)
+	}
+
+	return stored, false, nil
+}
+
+func (i *IdempotencyRepository) Complete(ctx context.Context, userId uuid.UUID, key string, resp domain.IdempotentResponse) error {
+	headers, err := json.Marshal(resp.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response headers: %w", err)
+	}
+
+	query := `UPDATE idempotency_keys SET status_code = $1, response_headers = $2, response_body = $3
+	WHERE user_id = $4 AND key = $5`
+
+	if _, err := i.db.ExecContext(ctx, query, resp.StatusCode, headers, resp.Body, userId, key); err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+// Release forgets a reservation whose request failed, so that a retry runs again.
+func (i *IdempotencyRepository) Release(ctx context.Context, userId uuid.UUID, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND status_code IS NULL`
+
+	if _, err := i.db.ExecContext(ctx, query, userId, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (i *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at < NOW()`
+
+	result, err := i.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return deleted, nil
+}
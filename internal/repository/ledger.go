@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"wallet-service/internal/domain"
+)
+
+// PostTransaction journals a balanced transaction and applies it to the
+// balances of the wallets it touches, all in one database transaction. The
+// wallets are locked in a fixed order, so concurrent postings cannot deadlock.
+func (w *WalletDB) PostTransaction(ctx context.Context, transaction domain.Transaction) (domain.Transaction, error) {
+	tx, err := w.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var walletIds []uuid.UUID
+
+	for _, entry := range transaction.Entries {
+		if entry.WalletId != nil {
+			walletIds = append(walletIds, *entry.WalletId)
+		}
+	}
+
+	wallets, err := lockWallets(ctx, tx, walletIds...)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	if err := applyTransaction(ctx, tx, &transaction, wallets); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.Transaction{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// RecomputeBalance rebuilds the stored wallet balance from its ledger entries.
+func (w *WalletDB) RecomputeBalance(ctx context.Context, walletId uuid.UUID) (domain.Wallet, error) {
+	tx, err := w.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	wallet, err := lockWallet(ctx, tx, walletId)
+	if err != nil {
+		return domain.Wallet{}, err
+	}
+
+	sumQuery := `SELECT COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0)
+	FROM ledger_entries
+	WHERE wallet_id = $1`
+
+	var ledgerBalance int64
+
+	if err := tx.GetContext(ctx, &ledgerBalance, sumQuery, walletId); err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to sum ledger entries: %w", err)
+	}
+
+	drift := ledgerBalance - wallet.Balance.Amount
+
+	if drift != 0 {
+		if err := updateBalance(ctx, tx, &wallet, drift); err != nil {
+			return domain.Wallet{}, err
+		}
+
+		if err := insertWalletEvent(ctx, tx, domain.WalletBalanceChanged, wallet, nil); err != nil {
+			return domain.Wallet{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return wallet, nil
+}
+
+// applyTransaction journals the transaction and moves the balances of the
+// affected wallets, which must already be locked by the caller.
+func applyTransaction(ctx context.Context, tx *sqlx.Tx, transaction *domain.Transaction, wallets map[uuid.UUID]*domain.Wallet) error {
+	if err := transaction.Validate(); err != nil {
+		return err
+	}
+
+	transactionQuery := `INSERT INTO transactions (id, type)
+	VALUES ($1, $2)
+	RETURNING created_at`
+
+	if err := tx.QueryRowContext(ctx, transactionQuery, transaction.Id, transaction.Type).Scan(&transaction.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert transaction: %w", err)
+	}
+
+	entryQuery := `INSERT INTO ledger_entries (transaction_id, wallet_id, direction, amount, currency)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id, created_at`
+
+	for i := range transaction.Entries {
+		entry := &transaction.Entries[i]
+		entry.TransactionId = transaction.Id
+
+		if err := tx.QueryRowContext(ctx, entryQuery,
+			entry.TransactionId,
+			entry.WalletId,
+			entry.Direction,
+			entry.Amount.Amount,
+			entry.Amount.Currency).Scan(&entry.Id, &entry.CreatedAt); err != nil {
+			return fmt.Errorf("failed to insert ledger entry: %w", err)
+		}
+
+		if entry.WalletId == nil {
+			continue
+		}
+
+		wallet, ok := wallets[*entry.WalletId]
+		if !ok {
+			return fmt.Errorf("wallet %s is not locked for the transaction", entry.WalletId)
+		}
+
+		if wallet.Status == domain.WalletFrozen {
+			return domain.ErrWalletFrozen
+		}
+
+		if wallet.Currency != entry.Amount.Currency {
+			return domain.ErrCurrencyMismatch
+		}
+
+		if err := updateBalance(ctx, tx, wallet, entry.Delta()); err != nil {
+			return err
+		}
+
+		if wallet.Balance.IsNegative() {
+			return domain.ErrInsufficientFunds
+		}
+	}
+
+	var notified []uuid.UUID
+
+	for _, entry := range transaction.Entries {
+		if entry.WalletId == nil || slices.Contains(notified, *entry.WalletId) {
+			continue
+		}
+
+		notified = append(notified, *entry.WalletId)
+
+		if err := insertWalletEvent(ctx, tx, domain.WalletBalanceChanged, *wallets[*entry.WalletId], &transaction.Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type transactionCursor struct {
+	EntryId int64 `json:"id"`
+}
+
+// GetTransactions lists the ledger entries of a wallet newest first. Entries are
+// paged by their id, which grows with every posting.
+func (w *WalletDB) GetTransactions(ctx context.Context, walletId uuid.UUID, filter domain.TransactionFilter) (domain.TransactionPage, error) {
+	var afterEntryId *int64
+
+	if filter.Cursor != "" {
+		var cursor transactionCursor
+
+		if err := decodeCursor(filter.Cursor, &cursor); err != nil {
+			return domain.TransactionPage{}, err
+		}
+
+		afterEntryId = &cursor.EntryId
+	}
+
+	types := make([]string, 0, len(filter.Types))
+	for _, txType := range filter.Types {
+		types = append(types, string(txType))
+	}
+
+	query := `SELECT id, transaction_id, type, amount, currency, counterparty_wallet_id, created_at
+	FROM (
+		SELECT e.id, e.transaction_id, e.amount, e.currency, e.created_at,
+			CASE
+				WHEN t.type = 'transfer' AND e.direction = 'credit' THEN 'transfer-in'
+				WHEN t.type = 'transfer' THEN 'transfer-out'
+				ELSE t.type
+			END AS type,
+			o.wallet_id AS counterparty_wallet_id
+		FROM ledger_entries e
+		JOIN transactions t ON t.id = e.transaction_id
+		LEFT JOIN ledger_entries o ON o.transaction_id = e.transaction_id AND o.id <> e.id
+		WHERE e.wallet_id = $1
+	) history
+	WHERE ($2::BIGINT IS NULL OR id < $2)
+	AND ($3::TIMESTAMPTZ IS NULL OR created_at >= $3)
+	AND ($4::TIMESTAMPTZ IS NULL OR created_at < $4)
+	AND (cardinality($5::TEXT[]) = 0 OR type = ANY($5))
+	ORDER BY id DESC
+	LIMIT $6`
+
+	// One extra row tells whether there is a next page.
+	rows, err := w.db.QueryContext(ctx, query, walletId, afterEntryId, filter.From, filter.To, pq.Array(types), filter.Limit+1)
+	if err != nil {
+		return domain.TransactionPage{}, fmt.Errorf("failed to get wallet transactions: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	page := domain.TransactionPage{
+		Transactions: make([]domain.WalletTransaction, 0, filter.Limit),
+	}
+
+	var lastEntryId int64
+
+	for rows.Next() {
+		if len(page.Transactions) == filter.Limit {
+			cursor, err := encodeCursor(transactionCursor{EntryId: lastEntryId})
+			if err != nil {
+				return domain.TransactionPage{}, err
+			}
+
+			page.NextCursor = cursor
+
+			break
+		}
+
+		var transaction domain.WalletTransaction
+
+		if err := rows.Scan(
+			&lastEntryId,
+			&transaction.Id,
+			&transaction.Type,
+			&transaction.Amount.Amount,
+			&transaction.Amount.Currency,
+			&transaction.CounterpartyWalletId,
+			&transaction.CreatedAt); err != nil {
+			return domain.TransactionPage{}, fmt.Errorf("failed to scan wallet transaction: %w", err)
+		}
+
+		page.Transactions = append(page.Transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return domain.TransactionPage{}, fmt.Errorf("failed to get wallet transactions: %w", err)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"wallet-service/internal/domain"
+)
+
+type OutboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+// RelayPending hands the oldest unsent messages to publish and marks them sent
+// once publish succeeds. A transaction-level advisory lock keeps concurrent
+// relays from publishing the same wallet's events out of order; a relay that
+// cannot take it relays nothing. When publish fails nothing is marked, so the
+// messages are published again on the next call.
+func (o *OutboxRepository) RelayPending(ctx context.Context, limit int,
+	publish func(ctx context.Context, messages []domain.OutboxMessage) error,
+) (int, error) {
+	tx, err := o.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var locked bool
+
+	if err := tx.GetContext(ctx, &locked, `SELECT pg_try_advisory_xact_lock(hashtext('outbox_relay'))`); err != nil {
+		return 0, fmt.Errorf("failed to lock the outbox: %w", err)
+	}
+
+	if !locked {
+		return 0, nil
+	}
+
+	var messages []domain.OutboxMessage
+
+	selectQuery := `SELECT id, aggregate_id, event_type, payload, created_at, sent_at
+	FROM outbox
+	WHERE sent_at IS NULL
+	ORDER BY id
+	LIMIT $1`
+
+	if err := tx.SelectContext(ctx, &messages, selectQuery, limit); err != nil {
+		return 0, fmt.Errorf("failed to get pending outbox messages: %w", err)
+	}
+
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	if err := publish(ctx, messages); err != nil {
+		return 0, err
+	}
+
+	ids := make([]int64, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.Id)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE outbox SET sent_at = NOW() WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+		return 0, fmt.Errorf("failed to mark outbox messages sent: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(messages), nil
+}
+
+// DeleteSent deletes the messages sent more than retention ago.
+func (o *OutboxRepository) DeleteSent(ctx context.Context, retention time.Duration) (int64, error) {
+	query := `DELETE FROM outbox WHERE sent_at < NOW() - make_interval(secs => $1)`
+
+	result, err := o.db.ExecContext(ctx, query, retention.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sent outbox messages: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sent outbox messages: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// insertWalletEvent records the event in the outbox within the transaction that
+// changed the wallet, so the event exists if and only if the change does.
+func insertWalletEvent(ctx context.Context, tx *sqlx.Tx, eventType domain.WalletEventType, wallet domain.Wallet,
+	transactionId *uuid.UUID,
+) error {
+	event := domain.NewWalletEvent(eventType, wallet)
+	event.TransactionId = transactionId
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the wallet event: %w", err)
+	}
+
+	query := `INSERT INTO outbox (aggregate_id, event_type, payload)
+	VALUES ($1, $2, $3)`
+
+	if _, err := tx.ExecContext(ctx, query, wallet.Id, eventType, payload); err != nil {
+		return fmt.Errorf("failed to insert outbox message: %w", err)
+	}
+
+	return nil
+}
@@ -2,7 +2,11 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -19,20 +23,69 @@ func NewUsersRepository(psql *sqlx.DB) *UsersRepository {
 	}
 }
 
-func (u *UsersRepository) UpsertUser(ctx context.Context, user domain.User) error {
+// UpsertUser stores the user state carried by an event that occurred at
+// eventAt. The write is skipped when the stored state comes from an event at
+// least as recent, so redelivered or reordered events cannot roll a user back.
+// It reports whether the state was applied.
+func (u *UsersRepository) UpsertUser(ctx context.Context, user domain.User, eventAt time.Time) (bool, error) {
 	query := `INSERT INTO users
-	(id, blocked_at, deleted_at)
-	VALUES ($1, $2, $3)
+	(id, blocked_at, deleted_at, last_event_at)
+	VALUES ($1, $2, $3, $4)
 	ON CONFLICT (id) DO UPDATE SET
 		blocked_at = excluded.blocked_at,
-		deleted_at = excluded.deleted_at`
+		deleted_at = excluded.deleted_at,
+		last_event_at = excluded.last_event_at
+	WHERE users.last_event_at IS NULL
+	OR users.last_event_at < excluded.last_event_at`
 
-	_, err := u.psql.ExecContext(ctx, query, user.Id, user.BlockedAt, user.DeletedAt)
+	result, err := u.psql.ExecContext(ctx, query, user.Id, user.BlockedAt, user.DeletedAt, eventAt)
 	if err != nil {
-		return fmt.Errorf("failed to UpsertUser: %w", err)
+		return false, fmt.Errorf("failed to UpsertUser: %w", err)
 	}
 
-	return nil
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected == 1, nil
+}
+
+// UpsertUsers applies a batch of changes in one statement, skipping stale ones
+// like UpsertUser. The changes must be for distinct users. It returns the ids
+// of the users whose change was applied.
+func (u *UsersRepository) UpsertUsers(ctx context.Context, changes []domain.UserChange) ([]uuid.UUID, error) {
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	values := make([]string, 0, len(changes))
+	args := make([]any, 0, len(changes)*4)
+
+	for i, change := range changes {
+		values = append(values, fmt.Sprintf("($%d::UUID, $%d::TIMESTAMPTZ, $%d::TIMESTAMPTZ, $%d::TIMESTAMPTZ)",
+			i*4+1, i*4+2, i*4+3, i*4+4))
+		args = append(args, change.User.Id, change.User.BlockedAt, change.User.DeletedAt, change.EventAt)
+	}
+
+	query := `INSERT INTO users
+	(id, blocked_at, deleted_at, last_event_at)
+	VALUES ` + strings.Join(values, ", ") + `
+	ON CONFLICT (id) DO UPDATE SET
+		blocked_at = excluded.blocked_at,
+		deleted_at = excluded.deleted_at,
+		last_event_at = excluded.last_event_at
+	WHERE users.last_event_at IS NULL
+	OR users.last_event_at < excluded.last_event_at
+	RETURNING id`
+
+	var applied []uuid.UUID
+
+	if err := u.psql.SelectContext(ctx, &applied, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to UpsertUsers: %w", err)
+	}
+
+	return applied, nil
 }
 
 func (u *UsersRepository) GetUser(ctx context.Context, userId uuid.UUID) (domain.User, error) {
@@ -41,8 +94,25 @@ func (u *UsersRepository) GetUser(ctx context.Context, userId uuid.UUID) (domain
 	query := `SELECT id, blocked_at, deleted_at FROM users WHERE id = $1`
 
 	if err := u.psql.QueryRowContext(ctx, query, userId).Scan(&user.Id, &user.BlockedAt, &user.DeletedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.User{}, domain.ErrUserNotFound
+		}
+
 		return domain.User{}, fmt.Errorf("failed to get User: %w", err)
 	}
 
 	return user, nil
 }
+
+// ListUsers returns up to limit users that are not deleted.
+func (u *UsersRepository) ListUsers(ctx context.Context, limit int) ([]domain.User, error) {
+	var users []domain.User
+
+	query := `SELECT id, blocked_at, deleted_at FROM users WHERE deleted_at IS NULL LIMIT $1`
+
+	if err := u.psql.SelectContext(ctx, &users, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, nil
+}
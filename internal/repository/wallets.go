@@ -1,11 +1,18 @@
 package repository
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"wallet-service/internal/domain"
 )
 
@@ -29,11 +36,26 @@ func (w *WalletDB) CreateWallet(ctx context.Context, wallet domain.Wallet, userI
 		return domain.Wallet{}, fmt.Errorf("failed to parse userId from string to UUID: %w", err)
 	}
 
-	_, err = w.db.ExecContext(ctx, query,
+	tx, err := w.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	openingBalance := wallet.Balance
+	wallet.Balance = domain.NewMoney(0, wallet.Currency)
+	wallet.Tags = []string{}
+	wallet.Status = domain.WalletActive
+	wallet.Version = 1
+
+	_, err = tx.ExecContext(ctx, query,
 		wallet.Id,
 		userIdParsed,
 		wallet.Name,
-		wallet.Balance,
+		wallet.Balance.Amount,
 		wallet.Currency,
 		wallet.CreatedAt,
 		wallet.UpdatedAt,
@@ -42,13 +64,29 @@ func (w *WalletDB) CreateWallet(ctx context.Context, wallet domain.Wallet, userI
 		return domain.Wallet{}, fmt.Errorf("failed to insert User: %w", err)
 	}
 
+	wallet.UserId = userIdParsed.String()
+
+	if err := insertWalletEvent(ctx, tx, domain.WalletCreated, wallet, nil); err != nil {
+		return domain.Wallet{}, err
+	}
+
+	if openingBalance.IsPositive() {
+		transaction := domain.NewDepositTransaction(wallet.Id, openingBalance)
+
+		if err := applyTransaction(ctx, tx, &transaction, map[uuid.UUID]*domain.Wallet{wallet.Id: &wallet}); err != nil {
+			return domain.Wallet{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return wallet, nil
 }
 
 func (w *WalletDB) GetWallet(ctx context.Context, walletId uuid.UUID, userId string) (domain.Wallet, error) {
-	var wallet domain.Wallet
-
-	query := `SELECT id, user_id, name, balance, currency, created_at, updated_at, deleted_at
+	query := `SELECT ` + walletColumns + `
 	FROM wallets
 	WHERE id = $1
 	AND user_id = $2
@@ -59,83 +97,567 @@ func (w *WalletDB) GetWallet(ctx context.Context, walletId uuid.UUID, userId str
 		return domain.Wallet{}, fmt.Errorf("failed to parse userId from string to UUID: %w", err)
 	}
 
-	if err := w.db.QueryRowContext(ctx, query, walletId, userIdParsed).Scan(
-		&wallet.Id,
-		&wallet.UserId,
-		&wallet.Name,
-		&wallet.Balance,
-		&wallet.Currency,
-		&wallet.CreatedAt,
-		&wallet.UpdatedAt,
-		&wallet.DeletedAt); err != nil {
+	wallet, err := scanWallet(w.db.QueryRowContext(ctx, query, walletId, userIdParsed))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Wallet{}, domain.ErrWalletNotFound
+		}
+
 		return domain.Wallet{}, fmt.Errorf("failed to get the wallet: %w", err)
 	}
 
 	return wallet, nil
 }
 
-func (w *WalletDB) GetWallets(ctx context.Context, userId string) ([]domain.Wallet, error) {
-	var wallets []domain.Wallet
+type walletCursor struct {
+	Sort      domain.WalletSort `json:"sort"`
+	Order     domain.SortOrder  `json:"order"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Name      string            `json:"name"`
+	Balance   int64             `json:"balance"`
+	Id        uuid.UUID         `json:"id"`
+}
 
-	query := `SELECT name, balance, currency, created_at, updated_at, deleted_at
-	FROM wallets
-	WHERE user_id = $1 AND deleted_at IS NULL`
+// key returns the value of the sort column the cursor points past.
+func (c walletCursor) key() any {
+	switch c.Sort {
+	case domain.WalletSortName:
+		return c.Name
+	case domain.WalletSortBalance:
+		return c.Balance
+	default:
+		return c.CreatedAt
+	}
+}
 
+// GetWallets lists a page of the user's wallets ordered by filter.Sort. Wallets
+// are paged by the sort column with the id breaking ties, so a page never skips
+// or repeats a wallet however many share the same name or balance.
+func (w *WalletDB) GetWallets(ctx context.Context, userId string, filter domain.WalletFilter) (domain.WalletPage, error) {
 	userIdParsed, err := uuid.Parse(userId)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse userId from string to UUID: %w", err)
+		return domain.WalletPage{}, fmt.Errorf("failed to parse userId from string to UUID: %w", err)
 	}
 
-	if err := w.db.SelectContext(ctx, &wallets, query, userIdParsed); err != nil {
-		return nil, fmt.Errorf("failed to get all wallets: %w", err)
+	var minBalance, maxBalance *int64
+
+	if filter.MinBalance != nil {
+		minBalance = &filter.MinBalance.Amount
 	}
 
-	return wallets, nil
-}
+	if filter.MaxBalance != nil {
+		maxBalance = &filter.MaxBalance.Amount
+	}
+
+	// The sort column and direction come from the validated filter, never from
+	// the client verbatim, so they are safe to format into the query.
+	column, direction, comparison := string(filter.Sort), "ASC", ">"
+	if filter.Order == domain.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	args := []any{userIdParsed, filter.Currency, filter.NamePrefix, minBalance, maxBalance}
+
+	keyset := ""
+
+	if filter.Cursor != "" {
+		var cursor walletCursor
+
+		if err := decodeCursor(filter.Cursor, &cursor); err != nil {
+			return domain.WalletPage{}, err
+		}
+
+		if cursor.Sort != filter.Sort || cursor.Order != filter.Order {
+			return domain.WalletPage{}, fmt.Errorf("%w: the cursor belongs to another sort order", domain.ErrInvalidCursor)
+		}
+
+		args = append(args, cursor.key(), cursor.Id)
+		keyset = fmt.Sprintf(`AND (%s, id) %s ($6, $7)`, column, comparison)
+	}
+
+	// One extra row tells whether there is a next page.
+	args = append(args, filter.Limit+1)
+
+	query := fmt.Sprintf(`SELECT `+walletColumns+`
+	FROM wallets
+	WHERE user_id = $1 AND deleted_at IS NULL
+	AND ($2::TEXT = '' OR currency = $2)
+	AND starts_with(name, $3)
+	AND ($4::BIGINT IS NULL OR balance >= $4)
+	AND ($5::BIGINT IS NULL OR balance <= $5)
+	%[1]s
+	ORDER BY %[2]s %[3]s, id %[3]s
+	LIMIT $%[4]d`, keyset, column, direction, len(args))
+
+	rows, err := w.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return domain.WalletPage{}, fmt.Errorf("failed to get all wallets: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	page := domain.WalletPage{
+		Wallets: make([]domain.Wallet, 0, filter.Limit),
+	}
+
+	for rows.Next() {
+		if len(page.Wallets) == filter.Limit {
+			last := page.Wallets[len(page.Wallets)-1]
+
+			cursor, err := encodeCursor(walletCursor{
+				Sort:      filter.Sort,
+				Order:     filter.Order,
+				CreatedAt: last.CreatedAt,
+				Name:      last.Name,
+				Balance:   last.Balance.Amount,
+				Id:        last.Id,
+			})
+			if err != nil {
+				return domain.WalletPage{}, err
+			}
+
+			page.NextCursor = cursor
+
+			break
+		}
+
+		wallet, err := scanWallet(rows)
+		if err != nil {
+			return domain.WalletPage{}, err
+		}
+
+		page.Wallets = append(page.Wallets, wallet)
+	}
 
-func (w *WalletDB) UpdateWallet(ctx context.Context, walletId uuid.UUID, userId string, wallet domain.WalletUpdate) (domain.Wallet, error) {
-	newWallet := domain.Wallet{
-		Name: wallet.Name,
+	if err := rows.Err(); err != nil {
+		return domain.WalletPage{}, fmt.Errorf("failed to get all wallets: %w", err)
 	}
 
+	return page, nil
+}
+
+// UpdateWallet applies the patch if the wallet is still at version, or at any
+// version with domain.AnyVersion. Making the wallet the default one takes the
+// flag away from the user's previous default wallet in the same transaction.
+func (w *WalletDB) UpdateWallet(ctx context.Context, walletId uuid.UUID, userId string, patch domain.WalletPatch,
+	version int64,
+) (domain.Wallet, error) {
 	userIdParsed, err := uuid.Parse(userId)
 	if err != nil {
 		return domain.Wallet{}, fmt.Errorf("failed to parse userId from string to UUID: %w", err)
 	}
 
-	query := `UPDATE wallets SET name = $1
-	WHERE id = $2
-	AND user_id = $3
-	AND deleted_at IS NULL`
+	var (
+		set  []string
+		args []any
+	)
+
+	// Only the patched columns are written; their placeholders follow the three
+	// that changeWallet reserves.
+	assign := func(column string, value any) {
+		args = append(args, value)
+		set = append(set, fmt.Sprintf("%s = $%d", column, len(args)+3))
+	}
+
+	if patch.Name != nil {
+		assign("name", *patch.Name)
+	}
+
+	if patch.Description != nil {
+		assign("description", *patch.Description)
+	}
+
+	if patch.Tags != nil {
+		assign("tags", pq.Array(*patch.Tags))
+	}
+
+	if patch.Status != nil {
+		assign("status", *patch.Status)
+	}
+
+	if patch.IsDefault != nil {
+		assign("is_default", *patch.IsDefault)
+	}
+
+	set = append(set, "updated_at = NOW()")
+
+	tx, err := w.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if patch.IsDefault != nil && *patch.IsDefault {
+		if err := clearDefaultWallet(ctx, tx, walletId, userIdParsed); err != nil {
+			return domain.Wallet{}, err
+		}
+	}
+
+	wallet, err := changeWallet(ctx, tx, domain.WalletUpdated, walletId, userIdParsed, version,
+		strings.Join(set, ", "), args...)
+	if err != nil {
+		return domain.Wallet{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
-	if _, err := w.db.ExecContext(ctx, query, newWallet.Name, walletId, userIdParsed); err != nil {
-		return domain.Wallet{}, fmt.Errorf("failed to update the wallet: %w", err)
+	return wallet, nil
+}
+
+func (w *WalletDB) DeleteWallet(ctx context.Context, walletId uuid.UUID, userId string, version int64) error {
+	userIdParsed, err := uuid.Parse(userId)
+	if err != nil {
+		return fmt.Errorf("failed to parse userId from string to UUID: %w", err)
+	}
+
+	tx, err := w.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Wallets are only soft-deleted: their ledger entries must stay auditable.
+	if _, err := changeWallet(ctx, tx, domain.WalletDeleted, walletId, userIdParsed, version, `deleted_at = NOW()`); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return newWallet, nil
+	return nil
 }
 
-func (w *WalletDB) DeleteWallet(ctx context.Context, walletId uuid.UUID, userId string) error {
-	query1 := `UPDATE wallets SET deleted_at = NOW()
+// changeWallet applies set, whose placeholders start at $4, to a live wallet of
+// the user, bumps its version and records the changed wallet in the outbox. A
+// wallet that is missing, deleted or owned by another user is reported as not
+// found; one that exists at another version than the expected one, as a
+// version mismatch.
+func changeWallet(ctx context.Context, tx *sqlx.Tx, eventType domain.WalletEventType, walletId, userId uuid.UUID,
+	version int64, set string, args ...any,
+) (domain.Wallet, error) {
+	query := `UPDATE wallets SET ` + set + `, version = version + 1
 	WHERE id = $1
 	AND user_id = $2
-	AND deleted_at IS NULL`
+	AND deleted_at IS NULL
+	AND ($3::BIGINT = 0 OR version = $3)
+	RETURNING ` + walletColumns
 
-	userIdParsed, err := uuid.Parse(userId)
+	wallet, err := scanWallet(tx.QueryRowContext(ctx, query, append([]any{walletId, userId, version}, args...)...))
 	if err != nil {
-		return fmt.Errorf("failed to parse userId from string to UUID: %w", err)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return domain.Wallet{}, fmt.Errorf("failed to update the wallet: %w", err)
+		}
+
+		if version == domain.AnyVersion {
+			return domain.Wallet{}, domain.ErrWalletNotFound
+		}
+
+		existsQuery := `SELECT EXISTS (
+			SELECT 1 FROM wallets WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+		)`
+
+		var exists bool
+
+		if err := tx.GetContext(ctx, &exists, existsQuery, walletId, userId); err != nil {
+			return domain.Wallet{}, fmt.Errorf("failed to update the wallet: %w", err)
+		}
+
+		if exists {
+			return domain.Wallet{}, domain.ErrWalletVersionMismatch
+		}
+
+		return domain.Wallet{}, domain.ErrWalletNotFound
 	}
 
-	if _, err := w.db.ExecContext(ctx, query1, walletId, userIdParsed); err != nil {
-		return fmt.Errorf("failed to update deleted_at column: %w", err)
+	if err := insertWalletEvent(ctx, tx, eventType, wallet, nil); err != nil {
+		return domain.Wallet{}, err
 	}
 
-	query2 := `DELETE FROM wallets
-	WHERE id = $1 AND user_id = $2`
+	return wallet, nil
+}
 
-	if _, err = w.db.ExecContext(ctx, query2, walletId, userIdParsed); err != nil {
-		return fmt.Errorf("failed to delete wallet: %w", err)
+// clearDefaultWallet takes the default flag away from every other wallet of the
+// user. The user row is locked first so that concurrent requests making
+// different wallets the default one take turns instead of both succeeding.
+func clearDefaultWallet(ctx context.Context, tx *sqlx.Tx, walletId, userId uuid.UUID) error {
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userId); err != nil {
+		return fmt.Errorf("failed to lock the user: %w", err)
+	}
+
+	query := `UPDATE wallets SET is_default = false, version = version + 1, updated_at = NOW()
+	WHERE user_id = $1
+	AND id <> $2
+	AND is_default
+	AND deleted_at IS NULL
+	RETURNING ` + walletColumns
+
+	if _, err := changeWallets(ctx, tx, domain.WalletUpdated, query, userId, walletId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FreezeUserWallets freezes every live wallet of the user and flags it for
+// closure. It returns the number of wallets that were frozen.
+func (w *WalletDB) FreezeUserWallets(ctx context.Context, userId uuid.UUID) (int64, error) {
+	query := `UPDATE wallets
+	SET status = $1, closure_requested_at = COALESCE(closure_requested_at, NOW()), version = version + 1, updated_at = NOW()
+	WHERE user_id = $2
+	AND deleted_at IS NULL
+	AND status <> $1
+	RETURNING ` + walletColumns
+
+	tx, err := w.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	frozen, err := changeWallets(ctx, tx, domain.WalletFrozenEvent, query, domain.WalletFrozen, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int64(len(frozen)), nil
+}
+
+// changeWallets runs an UPDATE returning walletColumns and records every
+// changed wallet in the outbox.
+func changeWallets(ctx context.Context, tx *sqlx.Tx, eventType domain.WalletEventType, query string,
+	args ...any,
+) ([]domain.Wallet, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update the wallets: %w", err)
+	}
+
+	var changed []domain.Wallet
+
+	for rows.Next() {
+		wallet, err := scanWallet(rows)
+		if err != nil {
+			_ = rows.Close()
+
+			return nil, err
+		}
+
+		changed = append(changed, wallet)
+	}
+
+	// The rows hold the connection of the transaction, so they are closed
+	// before the events are inserted.
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("failed to update the wallets: %w", err)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to update the wallets: %w", err)
+	}
+
+	for _, wallet := range changed {
+		if err := insertWalletEvent(ctx, tx, eventType, wallet, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return changed, nil
+}
+
+func (w *WalletDB) Deposit(ctx context.Context, walletId uuid.UUID, userId string, amount domain.Money) (domain.Wallet, error) {
+	return w.changeBalance(ctx, walletId, userId, func(wallet domain.Wallet) domain.Transaction {
+		return domain.NewDepositTransaction(wallet.Id, amount)
+	})
+}
+
+func (w *WalletDB) Withdraw(ctx context.Context, walletId uuid.UUID, userId string, amount domain.Money) (domain.Wallet, error) {
+	return w.changeBalance(ctx, walletId, userId, func(wallet domain.Wallet) domain.Transaction {
+		return domain.NewWithdrawalTransaction(wallet.Id, amount)
+	})
+}
+
+func (w *WalletDB) changeBalance(ctx context.Context, walletId uuid.UUID, userId string,
+	newTransaction func(wallet domain.Wallet) domain.Transaction,
+) (domain.Wallet, error) {
+	tx, err := w.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	wallets, err := lockWallets(ctx, tx, walletId)
+	if err != nil {
+		return domain.Wallet{}, err
+	}
+
+	wallet := wallets[walletId]
+
+	if wallet.UserId != userId {
+		return domain.Wallet{}, domain.ErrWalletNotFound
+	}
+
+	transaction := newTransaction(*wallet)
+
+	if err := applyTransaction(ctx, tx, &transaction, wallets); err != nil {
+		return domain.Wallet{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return *wallet, nil
+}
+
+func (w *WalletDB) Transfer(ctx context.Context, transfer domain.Transfer, userId string) (domain.TransferResult, error) {
+	tx, err := w.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.TransferResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	wallets, err := lockWallets(ctx, tx, transfer.FromWalletId, transfer.ToWalletId)
+	if err != nil {
+		return domain.TransferResult{}, err
+	}
+
+	from, to := wallets[transfer.FromWalletId], wallets[transfer.ToWalletId]
+
+	// Transfers move money between wallets of one user. A wallet of another
+	// user is reported like a missing one, so that its existence is not leaked.
+	if from.UserId != userId || to.UserId != userId {
+		return domain.TransferResult{}, domain.ErrWalletNotFound
+	}
+
+	if from.Currency != to.Currency {
+		return domain.TransferResult{}, domain.ErrCurrencyMismatch
+	}
+
+	transaction := domain.NewTransferTransaction(from.Id, to.Id, transfer.Amount)
+
+	if err := applyTransaction(ctx, tx, &transaction, wallets); err != nil {
+		return domain.TransferResult{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.TransferResult{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return domain.TransferResult{
+		From: *from,
+		To:   *to,
+	}, nil
+}
+
+// lockWallets locks the wallets in UUID order, so that two transactions touching
+// the same pair of wallets in opposite directions cannot deadlock each other.
+func lockWallets(ctx context.Context, tx *sqlx.Tx, walletIds ...uuid.UUID) (map[uuid.UUID]*domain.Wallet, error) {
+	lockOrder := slices.Clone(walletIds)
+	slices.SortFunc(lockOrder, func(a, b uuid.UUID) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	lockOrder = slices.Compact(lockOrder)
+
+	wallets := make(map[uuid.UUID]*domain.Wallet, len(lockOrder))
+
+	for _, walletId := range lockOrder {
+		wallet, err := lockWallet(ctx, tx, walletId)
+		if err != nil {
+			return nil, err
+		}
+
+		wallets[walletId] = &wallet
+	}
+
+	return wallets, nil
+}
+
+func lockWallet(ctx context.Context, tx *sqlx.Tx, walletId uuid.UUID) (domain.Wallet, error) {
+	query := `SELECT ` + walletColumns + `
+	FROM wallets
+	WHERE id = $1
+	AND deleted_at IS NULL
+	FOR UPDATE`
+
+	wallet, err := scanWallet(tx.QueryRowContext(ctx, query, walletId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Wallet{}, domain.ErrWalletNotFound
+		}
+
+		return domain.Wallet{}, fmt.Errorf("failed to lock the wallet: %w", err)
+	}
+
+	return wallet, nil
+}
+
+func updateBalance(ctx context.Context, tx *sqlx.Tx, wallet *domain.Wallet, delta int64) error {
+	query := `UPDATE wallets SET balance = balance + $1, version = version + 1, updated_at = NOW()
+	WHERE id = $2
+	RETURNING balance, version, updated_at`
+
+	if err := tx.QueryRowContext(ctx, query, delta, wallet.Id).Scan(&wallet.Balance.Amount, &wallet.Version, &wallet.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to update the wallet balance: %w", err)
 	}
 
 	return nil
 }
+
+const walletColumns = `id, user_id, name, description, tags, is_default, balance, currency, status, closure_requested_at,
+	version, created_at, updated_at, deleted_at`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanWallet reads a row selected with walletColumns. The balance is stored in
+// minor units and takes its currency from the wallet.
+func scanWallet(row scanner) (domain.Wallet, error) {
+	var wallet domain.Wallet
+
+	if err := row.Scan(
+		&wallet.Id,
+		&wallet.UserId,
+		&wallet.Name,
+		&wallet.Description,
+		pq.Array(&wallet.Tags),
+		&wallet.IsDefault,
+		&wallet.Balance.Amount,
+		&wallet.Currency,
+		&wallet.Status,
+		&wallet.ClosureRequestedAt,
+		&wallet.Version,
+		&wallet.CreatedAt,
+		&wallet.UpdatedAt,
+		&wallet.DeletedAt); err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to scan the wallet: %w", err)
+	}
+
+	wallet.Balance.Currency = wallet.Currency
+
+	return wallet, nil
+}
@@ -16,14 +16,23 @@ var (
 	ErrGetWallets   = errors.New("failed to get a wallets")
 	ErrUpdateWallet = errors.New("failed to update the wallet")
 	ErrDeleteWallet = errors.New("failed to delete the wallet")
+	ErrDeposit      = errors.New("failed to deposit to the wallet")
+	ErrWithdraw     = errors.New("failed to withdraw from the wallet")
+	ErrTransfer     = errors.New("failed to transfer between wallets")
+
+	ErrGetTransactions = errors.New("failed to get wallet transactions")
 )
 
 type wallets interface {
 	CreateWallet(ctx context.Context, wallet domain.Wallet, userId string) (domain.Wallet, error)
 	GetWallet(ctx context.Context, walletId uuid.UUID, userId string) (domain.Wallet, error)
-	GetWallets(ctx context.Context, userId string) ([]domain.Wallet, error)
-	UpdateWallet(ctx context.Context, walletId uuid.UUID, userId string, wallet domain.WalletUpdate) (domain.Wallet, error)
-	DeleteWallet(ctx context.Context, walletId uuid.UUID, userId string) error
+	GetWallets(ctx context.Context, userId string, filter domain.WalletFilter) (domain.WalletPage, error)
+	UpdateWallet(ctx context.Context, walletId uuid.UUID, userId string, patch domain.WalletPatch, version int64) (domain.Wallet, error)
+	DeleteWallet(ctx context.Context, walletId uuid.UUID, userId string, version int64) error
+	Deposit(ctx context.Context, walletId uuid.UUID, userId string, amount domain.Money) (domain.Wallet, error)
+	Withdraw(ctx context.Context, walletId uuid.UUID, userId string, amount domain.Money) (domain.Wallet, error)
+	Transfer(ctx context.Context, transfer domain.Transfer, userId string) (domain.TransferResult, error)
+	GetTransactions(ctx context.Context, walletId uuid.UUID, filter domain.TransactionFilter) (domain.TransactionPage, error)
 }
 
 type Service struct {
@@ -38,7 +47,46 @@ func New(repo *repository.UsersRepository, walletDb wallets) *Service {
 	}
 }
 
+type access bool
+
+const (
+	readOnly access = false
+	mutation access = true
+)
+
+// checkUser hides everything from deleted users and lets blocked users read
+// their wallets but not change them.
+func (s *Service) checkUser(ctx context.Context, userId string, access access) error {
+	userIdParsed, err := uuid.Parse(userId)
+	if err != nil {
+		return fmt.Errorf("failed to parse userId from string to UUID: %w", err)
+	}
+
+	user, err := s.repo.GetUser(ctx, userIdParsed)
+	if err != nil {
+		return err
+	}
+
+	if user.DeletedAt != nil {
+		return domain.ErrUserDeleted
+	}
+
+	if access == mutation && user.BlockedAt != nil {
+		return domain.ErrUserBlocked
+	}
+
+	return nil
+}
+
 func (s *Service) CreateWallet(ctx context.Context, wallet domain.Wallet, userId string) (domain.Wallet, error) {
+	if err := s.checkUser(ctx, userId, mutation); err != nil {
+		return domain.Wallet{}, fmt.Errorf("%w: %w", ErrCreateWallet, err)
+	}
+
+	if err := wallet.Validate(); err != nil {
+		return domain.Wallet{}, fmt.Errorf("%w: %w", ErrCreateWallet, err)
+	}
+
 	newWallet, err := s.walletDb.CreateWallet(ctx, wallet, userId)
 	if err != nil {
 		return domain.Wallet{}, fmt.Errorf("%w: %w", ErrCreateWallet, err)
@@ -48,6 +96,10 @@ func (s *Service) CreateWallet(ctx context.Context, wallet domain.Wallet, userId
 }
 
 func (s *Service) GetWallet(ctx context.Context, walletId uuid.UUID, userId string) (domain.Wallet, error) {
+	if err := s.checkUser(ctx, userId, readOnly); err != nil {
+		return domain.Wallet{}, fmt.Errorf("%w: %w", ErrGetWallet, err)
+	}
+
 	wallet, err := s.walletDb.GetWallet(ctx, walletId, userId)
 	if err != nil {
 		return domain.Wallet{}, fmt.Errorf("%w: %w", ErrGetWallet, err)
@@ -56,17 +108,94 @@ func (s *Service) GetWallet(ctx context.Context, walletId uuid.UUID, userId stri
 	return wallet, nil
 }
 
-func (s *Service) GetWallets(ctx context.Context, userId string) ([]domain.Wallet, error) {
-	wallets, err := s.walletDb.GetWallets(ctx, userId)
+func (s *Service) GetWallets(ctx context.Context, userId string, filter domain.WalletFilter) (domain.WalletPage, error) {
+	if err := s.checkUser(ctx, userId, readOnly); err != nil {
+		return domain.WalletPage{}, fmt.Errorf("%w: %w", ErrGetWallets, err)
+	}
+
+	if filter.Limit < 0 {
+		return domain.WalletPage{}, fmt.Errorf("%w: %w: %d", ErrGetWallets, domain.ErrInvalidLimit, filter.Limit)
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = domain.DefaultPageLimit
+	}
+
+	filter.Limit = min(filter.Limit, domain.MaxPageLimit)
+
+	if filter.Sort == "" {
+		filter.Sort = domain.WalletSortCreatedAt
+	}
+
+	if filter.Order == "" {
+		filter.Order = domain.SortAsc
+	}
+
+	if !filter.Sort.Valid() {
+		return domain.WalletPage{}, fmt.Errorf("%w: %w: unknown sort %q", ErrGetWallets, domain.ErrInvalidFilter, filter.Sort)
+	}
+
+	if !filter.Order.Valid() {
+		return domain.WalletPage{}, fmt.Errorf("%w: %w: unknown order %q", ErrGetWallets, domain.ErrInvalidFilter, filter.Order)
+	}
+
+	if filter.Currency != "" && !domain.IsKnownCurrency(filter.Currency) {
+		return domain.WalletPage{}, fmt.Errorf("%w: %w: %q", ErrGetWallets, domain.ErrUnknownCurrency, filter.Currency)
+	}
+
+	for _, bound := range []*domain.Money{filter.MinBalance, filter.MaxBalance} {
+		if bound != nil && bound.Currency != filter.Currency {
+			return domain.WalletPage{}, fmt.Errorf("%w: %w: balance bounds require the currency filter", ErrGetWallets, domain.ErrInvalidFilter)
+		}
+	}
+
+	if filter.MinBalance != nil && filter.MaxBalance != nil && filter.MinBalance.Amount > filter.MaxBalance.Amount {
+		return domain.WalletPage{}, fmt.Errorf("%w: %w: minBalance must not exceed maxBalance", ErrGetWallets, domain.ErrInvalidFilter)
+	}
+
+	page, err := s.walletDb.GetWallets(ctx, userId, filter)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrGetWallets, err)
+		return domain.WalletPage{}, fmt.Errorf("%w: %w", ErrGetWallets, err)
 	}
 
-	return wallets, nil
+	return page, nil
 }
 
-func (s *Service) UpdateWallet(ctx context.Context, walletId uuid.UUID, userId string, wallet domain.WalletUpdate) (domain.Wallet, error) {
-	updatedWallet, err := s.walletDb.UpdateWallet(ctx, walletId, userId, wallet)
+// UpdateWallet patches the wallet only if it is still at version, so that a
+// client cannot overwrite a change it has not seen. domain.AnyVersion skips the
+// check. An empty patch changes nothing and returns the wallet as it is.
+func (s *Service) UpdateWallet(ctx context.Context, walletId uuid.UUID, userId string, patch domain.WalletPatch,
+	version int64,
+) (domain.Wallet, error) {
+	if err := s.checkUser(ctx, userId, mutation); err != nil {
+		return domain.Wallet{}, fmt.Errorf("%w: %w", ErrUpdateWallet, err)
+	}
+
+	reactivating := patch.Status != nil && *patch.Status == domain.WalletActive
+
+	if patch.Empty() || reactivating {
+		wallet, err := s.walletDb.GetWallet(ctx, walletId, userId)
+		if err != nil {
+			return domain.Wallet{}, fmt.Errorf("%w: %w", ErrUpdateWallet, err)
+		}
+
+		if version != domain.AnyVersion && wallet.Version != version {
+			return domain.Wallet{}, fmt.Errorf("%w: %w", ErrUpdateWallet, domain.ErrWalletVersionMismatch)
+		}
+
+		// A wallet frozen because its user was deleted stays frozen until closed.
+		if reactivating && wallet.ClosureRequestedAt != nil {
+			return domain.Wallet{}, fmt.Errorf("%w: %w", ErrUpdateWallet, domain.NewFieldsError([]domain.FieldError{
+				{Field: "status", Message: "cannot be active for a wallet requested for closure"},
+			}))
+		}
+
+		if patch.Empty() {
+			return wallet, nil
+		}
+	}
+
+	updatedWallet, err := s.walletDb.UpdateWallet(ctx, walletId, userId, patch, version)
 	if err != nil {
 		return domain.Wallet{}, fmt.Errorf("%w: %w", ErrUpdateWallet, err)
 	}
@@ -74,11 +203,109 @@ func (s *Service) UpdateWallet(ctx context.Context, walletId uuid.UUID, userId s
 	return updatedWallet, nil
 }
 
-func (s *Service) DeleteWallet(ctx context.Context, walletId uuid.UUID, userId string) error {
-	err := s.walletDb.DeleteWallet(ctx, walletId, userId)
+func (s *Service) DeleteWallet(ctx context.Context, walletId uuid.UUID, userId string, version int64) error {
+	if err := s.checkUser(ctx, userId, mutation); err != nil {
+		return fmt.Errorf("%w: %w", ErrDeleteWallet, err)
+	}
+
+	err := s.walletDb.DeleteWallet(ctx, walletId, userId, version)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrDeleteWallet, err)
 	}
 
 	return nil
 }
+
+func (s *Service) Deposit(ctx context.Context, walletId uuid.UUID, userId string, amount domain.Money) (domain.Wallet, error) {
+	if err := s.checkUser(ctx, userId, mutation); err != nil {
+		return domain.Wallet{}, fmt.Errorf("%w: %w", ErrDeposit, err)
+	}
+
+	if !amount.IsPositive() {
+		return domain.Wallet{}, fmt.Errorf("%w: %w", ErrDeposit, domain.ErrInvalidAmount)
+	}
+
+	wallet, err := s.walletDb.Deposit(ctx, walletId, userId, amount)
+	if err != nil {
+		return domain.Wallet{}, fmt.Errorf("%w: %w", ErrDeposit, err)
+	}
+
+	return wallet, nil
+}
+
+func (s *Service) Withdraw(ctx context.Context, walletId uuid.UUID, userId string, amount domain.Money) (domain.Wallet, error) {
+	if err := s.checkUser(ctx, userId, mutation); err != nil {
+		return domain.Wallet{}, fmt.Errorf("%w: %w", ErrWithdraw, err)
+	}
+
+	if !amount.IsPositive() {
+		return domain.Wallet{}, fmt.Errorf("%w: %w", ErrWithdraw, domain.ErrInvalidAmount)
+	}
+
+	wallet, err := s.walletDb.Withdraw(ctx, walletId, userId, amount)
+	if err != nil {
+		return domain.Wallet{}, fmt.Errorf("%w: %w", ErrWithdraw, err)
+	}
+
+	return wallet, nil
+}
+
+func (s *Service) Transfer(ctx context.Context, transfer domain.Transfer, userId string) (domain.TransferResult, error) {
+	if err := s.checkUser(ctx, userId, mutation); err != nil {
+		return domain.TransferResult{}, fmt.Errorf("%w: %w", ErrTransfer, err)
+	}
+
+	if !transfer.Amount.IsPositive() {
+		return domain.TransferResult{}, fmt.Errorf("%w: %w", ErrTransfer, domain.ErrInvalidAmount)
+	}
+
+	if transfer.FromWalletId == transfer.ToWalletId {
+		return domain.TransferResult{}, fmt.Errorf("%w: %w", ErrTransfer, domain.ErrSameWallet)
+	}
+
+	result, err := s.walletDb.Transfer(ctx, transfer, userId)
+	if err != nil {
+		return domain.TransferResult{}, fmt.Errorf("%w: %w", ErrTransfer, err)
+	}
+
+	return result, nil
+}
+
+func (s *Service) GetTransactions(ctx context.Context, walletId uuid.UUID, userId string,
+	filter domain.TransactionFilter,
+) (domain.TransactionPage, error) {
+	if err := s.checkUser(ctx, userId, readOnly); err != nil {
+		return domain.TransactionPage{}, fmt.Errorf("%w: %w", ErrGetTransactions, err)
+	}
+
+	if filter.Limit < 0 {
+		return domain.TransactionPage{}, fmt.Errorf("%w: %w: %d", ErrGetTransactions, domain.ErrInvalidLimit, filter.Limit)
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = domain.DefaultPageLimit
+	}
+
+	filter.Limit = min(filter.Limit, domain.MaxPageLimit)
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return domain.TransactionPage{}, fmt.Errorf("%w: %w: from must be before to", ErrGetTransactions, domain.ErrInvalidFilter)
+	}
+
+	for _, txType := range filter.Types {
+		if !txType.Valid() {
+			return domain.TransactionPage{}, fmt.Errorf("%w: %w: unknown type %q", ErrGetTransactions, domain.ErrInvalidFilter, txType)
+		}
+	}
+
+	if _, err := s.walletDb.GetWallet(ctx, walletId, userId); err != nil {
+		return domain.TransactionPage{}, fmt.Errorf("%w: %w", ErrGetTransactions, err)
+	}
+
+	page, err := s.walletDb.GetTransactions(ctx, walletId, filter)
+	if err != nil {
+		return domain.TransactionPage{}, fmt.Errorf("%w: %w", ErrGetTransactions, err)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,213 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/events"
+)
+
+// workBatches is work for batch mode: it collects up to batchSize messages, or
+// whatever arrived within batchTimeout of the first one, and writes them with a
+// single upsert.
+func (c *Consumer) workBatches(ctx context.Context, fail context.CancelCauseFunc, queue <-chan kafka.Message,
+	tracker *offsetTracker,
+) {
+	batch := make([]kafka.Message, 0, c.batchSize)
+
+	timer := time.NewTimer(c.batchTimeout)
+	timer.Stop()
+
+	flush := func() {
+		timer.Stop()
+
+		if len(batch) == 0 {
+			return
+		}
+
+		handled, err := c.processBatch(ctx, batch)
+		if err != nil {
+			fail(err)
+		}
+
+		for _, msg := range handled {
+			tracker.markDone(msg)
+		}
+
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case msg, ok := <-queue:
+			if !ok {
+				flush()
+
+				return
+			}
+
+			if ctx.Err() != nil {
+				continue
+			}
+
+			if len(batch) == 0 {
+				timer.Reset(c.batchTimeout)
+			}
+
+			batch = append(batch, msg)
+
+			if len(batch) >= c.batchSize {
+				flush()
+			}
+		case <-timer.C:
+			flush()
+		}
+	}
+}
+
+// processBatch returns the messages it handled, which are all of them unless
+// shutdown cut the retries short or a message could not be dead-lettered.
+//
+// Poison messages are dead-lettered on their own. Of several events for the
+// same user only the latest is written and the older ones are skipped as
+// stale. If the batch keeps failing, its messages are handled one by one, so
+// that a single bad message ends up in the dead-letter topic instead of failing
+// the whole batch.
+func (c *Consumer) processBatch(ctx context.Context, msgs []kafka.Message) ([]kafka.Message, error) {
+	var (
+		deadLettered []kafka.Message
+		valid        []kafka.Message
+		superseded   []events.UserEvent
+	)
+
+	latest := make(map[uuid.UUID]events.UserEvent, len(msgs))
+
+	for _, msg := range msgs {
+		event, err := decode(msg)
+		if err != nil {
+			if err := c.deadLetter(ctx, msg, err, 1); err != nil {
+				return deadLettered, err
+			}
+
+			deadLettered = append(deadLettered, msg)
+
+			continue
+		}
+
+		valid = append(valid, msg)
+
+		userId := event.Payload.Id
+
+		current, ok := latest[userId]
+
+		switch {
+		case !ok:
+			latest[userId] = event
+		case event.OccurredAt.Before(current.OccurredAt):
+			superseded = append(superseded, event)
+		default:
+			superseded = append(superseded, current)
+			latest[userId] = event
+		}
+	}
+
+	batch := make([]events.UserEvent, 0, len(latest))
+	for _, event := range latest {
+		batch = append(batch, event)
+	}
+
+	// A retry after a failed freeze finds the users stored by an earlier
+	// attempt, so an event is stale only if no attempt applied it.
+	applied := make(map[uuid.UUID]bool, len(batch))
+
+	_, err := c.retry.do(ctx, func() error {
+		return c.applyBatch(context.WithoutCancel(ctx), batch, applied)
+	})
+	if err == nil {
+		// Skipped events are only counted once the batch is stored: handled one
+		// by one, each of them is reported by process.
+		for _, event := range batch {
+			if !applied[event.Payload.Id] {
+				c.skip(event)
+			}
+		}
+
+		for _, event := range superseded {
+			c.skip(event)
+		}
+
+		logrus.Printf("applied a batch of %d user events correlation: %s", len(batch), correlationIds(valid))
+
+		return msgs, nil
+	}
+
+	if ctx.Err() != nil {
+		logrus.Warnf("shutting down before a batch of %d messages succeeded, it will be redelivered: %v", len(valid), err)
+
+		return deadLettered, nil
+	}
+
+	logrus.Errorf("failed to apply a batch of %d messages, handling them one by one: %v", len(valid), err)
+
+	handled := deadLettered
+
+	for _, msg := range valid {
+		ok, err := c.process(ctx, msg)
+		if err != nil {
+			return handled, err
+		}
+
+		if ok {
+			handled = append(handled, msg)
+		}
+	}
+
+	return handled, nil
+}
+
+// applyBatch stores the users of the batch and marks the applied ones in
+// applied.
+func (c *Consumer) applyBatch(ctx context.Context, batch []events.UserEvent, applied map[uuid.UUID]bool) error {
+	changes := make([]domain.UserChange, 0, len(batch))
+	for _, event := range batch {
+		changes = append(changes, domain.UserChange{
+			User:    event.User(),
+			EventAt: event.OccurredAt,
+		})
+	}
+
+	userIds, err := c.repo.UpsertUsers(ctx, changes)
+	if err != nil {
+		return fmt.Errorf("failed to create or update the users: %w", err)
+	}
+
+	for _, userId := range userIds {
+		applied[userId] = true
+	}
+
+	for _, event := range batch {
+		if err := c.afterUpsert(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func correlationIds(msgs []kafka.Message) string {
+	ids := make([]string, 0, len(msgs))
+
+	for _, msg := range msgs {
+		if id := header(msg, events.HeaderCorrelationId); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	return strings.Join(ids, ",")
+}
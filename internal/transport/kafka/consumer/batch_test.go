@@ -0,0 +1,111 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/events"
+)
+
+func TestProcessBatchCountsSkippedEvents(t *testing.T) {
+	now := time.Now().UTC()
+
+	event := func(user domain.User, occurredAt time.Time) events.UserEvent {
+		event := events.NewUserEvent(events.UserCreated, user)
+		event.OccurredAt = occurredAt
+
+		return event
+	}
+
+	superseded := domain.User{Id: uuid.New()}
+	stale := domain.User{Id: uuid.New()}
+
+	msgs := []kafka.Message{
+		eventMessage(t, 0, event(superseded, now.Add(-time.Minute))),
+		eventMessage(t, 1, event(superseded, now)),
+		eventMessage(t, 2, event(superseded, now.Add(-time.Hour))),
+		eventMessage(t, 3, event(stale, now)),
+	}
+
+	repo := newFakeUsers()
+	repo.stale = []uuid.UUID{stale.Id}
+	close(repo.release)
+
+	c := &Consumer{
+		repo:       repo,
+		walletRepo: &fakeWallets{},
+		dlq:        &fakePublisher{},
+		retry:      retryPolicy{maxAttempts: 1},
+	}
+
+	handled, err := c.processBatch(context.Background(), msgs)
+	require.NoError(t, err)
+	require.Equal(t, msgs, handled)
+
+	require.Len(t, repo.changes, 2, "one write per user")
+
+	for _, change := range repo.changes {
+		require.Equal(t, now, change.EventAt, "the latest event of a user is written")
+	}
+
+	require.Equal(t, int64(3), c.SkippedEvents(),
+		"the two events superseded in the batch and the one the repository found stale")
+}
+
+func TestRetryAfterFailedFreezeIsNotSkipped(t *testing.T) {
+	deletedAt := time.Now().UTC()
+
+	for _, batchSize := range []int{1, 2} {
+		t.Run(fmt.Sprintf("batch size %d", batchSize), func(t *testing.T) {
+			user := domain.User{Id: uuid.New(), DeletedAt: &deletedAt}
+			msgs := []kafka.Message{
+				eventMessage(t, 0, events.NewUserEvent(events.UserDeleted, user)),
+				userMessage(t, 1, domain.User{Id: uuid.New()}),
+			}
+
+			repo := newFakeUsers()
+			close(repo.release)
+
+			// The user is stored, then freezing its wallets fails once and the
+			// retry stores it again.
+			wallets := &fakeWallets{failures: 1, err: errors.New("connection reset")}
+
+			c := &Consumer{
+				repo:       repo,
+				walletRepo: wallets,
+				dlq:        &fakePublisher{},
+				retry:      retryPolicy{maxAttempts: 3},
+				batchSize:  batchSize,
+			}
+
+			var handled []kafka.Message
+
+			if batchSize > 1 {
+				var err error
+
+				handled, err = c.processBatch(context.Background(), msgs)
+				require.NoError(t, err)
+			} else {
+				for _, msg := range msgs {
+					ok, err := c.process(context.Background(), msg)
+					require.NoError(t, err)
+					require.True(t, ok)
+
+					handled = append(handled, msg)
+				}
+			}
+
+			require.Equal(t, msgs, handled)
+			require.Equal(t, 2, wallets.calls, "the freeze is retried")
+			require.Len(t, repo.stored(), 2)
+			require.Zero(t, c.SkippedEvents(), "an event applied by an earlier attempt is not stale")
+		})
+	}
+}
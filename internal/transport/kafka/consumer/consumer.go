@@ -2,27 +2,56 @@ package consumer
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
 	configs "wallet-service/internal/config"
 	"wallet-service/internal/domain"
+	"wallet-service/internal/events"
 )
 
 type Consumer struct {
-	kf   *kafka.Reader
-	repo usersDb
+	kf         reader
+	repo       usersDb
+	walletRepo walletsDb
+	dlq        publisher
+	retry      retryPolicy
+
+	commitBatchSize int
+	commitInterval  time.Duration
+	concurrency     int
+	orderByKey      bool
+	batchSize       int
+	batchTimeout    time.Duration
+
+	skipped atomic.Int64
+}
+
+// reader is the part of kafka.Reader the consumers use.
+type reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
 }
 
 type usersDb interface {
-	UpsertUser(ctx context.Context, user domain.User) error
+	UpsertUser(ctx context.Context, user domain.User, eventAt time.Time) (bool, error)
+	UpsertUsers(ctx context.Context, changes []domain.UserChange) ([]uuid.UUID, error)
 	GetUser(ctx context.Context, user uuid.UUID) (domain.User, error)
 }
 
-func New(cfg *configs.Config, repo usersDb) *Consumer {
+type walletsDb interface {
+	FreezeUserWallets(ctx context.Context, userId uuid.UUID) (int64, error)
+}
+
+func New(cfg *configs.Config, repo usersDb, walletRepo walletsDb, dlq publisher) *Consumer {
 	kf := kafka.NewReader(kafka.ReaderConfig{
 		Brokers: cfg.Kafka.Brokers,
 		GroupID: cfg.Kafka.GroupID,
@@ -30,31 +59,321 @@ func New(cfg *configs.Config, repo usersDb) *Consumer {
 	})
 
 	return &Consumer{
-		kf:   kf,
-		repo: repo,
+		kf:              kf,
+		repo:            repo,
+		walletRepo:      walletRepo,
+		dlq:             dlq,
+		retry:           newRetryPolicy(cfg.Kafka),
+		commitBatchSize: cfg.Kafka.CommitBatchSize,
+		commitInterval:  cfg.Kafka.CommitInterval,
+		concurrency:     max(cfg.Kafka.Concurrency, 1),
+		orderByKey:      cfg.Kafka.Ordering == configs.OrderingKey,
+		batchSize:       max(cfg.Kafka.BatchSize, 1),
+		batchTimeout:    cfg.Kafka.BatchTimeout,
 	}
 }
 
+// Consume commits the offset of a message only after the message has been
+// written to the database, so a consumer killed at any point re-reads every
+// message it had not fully handled. Offsets are committed in batches of
+// commitBatchSize messages or every commitInterval, whichever comes first.
+//
+// Messages are handled by concurrency workers. All messages of a partition, or
+// with orderByKey all messages with the same key, go to the same worker and are
+// handled in order; messages without a key fall back to their partition. With
+// a batchSize above one, each worker writes its messages in batches.
+//
+// Cancelling ctx stops Consume gracefully: the messages being handled are
+// finished, the offsets handled so far are committed and Consume returns nil.
+//
+// A message that can never succeed, such as one that does not decode, is
+// forwarded to the dead-letter topic so that it does not halt the messages
+// behind it. A message that keeps failing for a transient reason, such as the
+// database being down, would fail the messages behind it as well: once the
+// retry policy is exhausted Consume stops with the error, without committing
+// the message, so that it is redelivered after a restart.
 func (c *Consumer) Consume(ctx context.Context) error {
-	logrus.Info("Consuming messages...")
+	logrus.Infof("Consuming messages with %d workers...", c.concurrency)
+
+	workCtx, fail := context.WithCancelCause(ctx)
+	defer fail(nil)
+
+	tracker := newOffsetTracker()
+	queues := make([]chan kafka.Message, c.concurrency)
+
+	var wg sync.WaitGroup
+
+	for i := range queues {
+		queues[i] = make(chan kafka.Message, c.commitBatchSize)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if c.batchSize > 1 {
+				c.workBatches(workCtx, fail, queues[i], tracker)
+			} else {
+				c.work(workCtx, fail, queues[i], tracker)
+			}
+		}()
+	}
+
+	err := c.dispatch(workCtx, queues, tracker)
+	if err != nil {
+		fail(err)
+	}
+
+	for _, queue := range queues {
+		close(queue)
+	}
+
+	wg.Wait()
+
+	if cause := context.Cause(workCtx); err == nil && ctx.Err() == nil && cause != nil {
+		err = cause
+	}
+
+	return errors.Join(err, c.commit(context.WithoutCancel(ctx), tracker.takeCommittable()))
+}
+
+// dispatch fetches messages and hands them to the workers until ctx is
+// cancelled, committing the offsets of handled messages on the way.
+func (c *Consumer) dispatch(ctx context.Context, queues []chan kafka.Message, tracker *offsetTracker) error {
+	lastCommit := time.Now()
 
 	for {
-		msg, err := c.kf.ReadMessage(ctx)
+		fetchCtx, cancel := context.WithDeadline(ctx, lastCommit.Add(c.commitInterval))
+		msg, err := c.kf.FetchMessage(fetchCtx)
+		cancel()
+
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			// The commit interval elapsed while waiting for the next message.
+			if errors.Is(err, context.DeadlineExceeded) {
+				if err := c.commit(ctx, tracker.takeCommittable()); err != nil {
+					return err
+				}
+
+				lastCommit = time.Now()
+
+				continue
+			}
+
 			return fmt.Errorf("failed to consume a messages: %w", err)
 		}
 
-		var user domain.User
-		if err := json.Unmarshal(msg.Value, &user); err != nil {
-			return fmt.Errorf("failed to unmarshal domain.User: %w", err)
+		tracker.add(msg)
+
+		select {
+		case queues[c.route(msg, len(queues))] <- msg:
+		case <-ctx.Done():
+			return nil
+		}
+
+		if tracker.uncommitted() >= c.commitBatchSize || time.Since(lastCommit) >= c.commitInterval {
+			if err := c.commit(ctx, tracker.takeCommittable()); err != nil {
+				return err
+			}
+
+			lastCommit = time.Now()
+		}
+	}
+}
+
+func (c *Consumer) route(msg kafka.Message, workers int) int {
+	if c.orderByKey && len(msg.Key) > 0 {
+		hash := fnv.New32a()
+		_, _ = hash.Write(msg.Key)
+
+		return int(hash.Sum32() % uint32(workers))
+	}
+
+	return msg.Partition % workers
+}
+
+// work handles the messages of its queue one by one. Once ctx is cancelled the
+// queued messages are left for redelivery, and a failure that must stop the
+// consumer cancels ctx with the failure as its cause.
+func (c *Consumer) work(ctx context.Context, fail context.CancelCauseFunc, queue <-chan kafka.Message,
+	tracker *offsetTracker,
+) {
+	for msg := range queue {
+		if ctx.Err() != nil {
+			continue
+		}
+
+		handled, err := c.process(ctx, msg)
+		if err != nil {
+			fail(err)
+
+			continue
 		}
 
-		if err := c.repo.UpsertUser(ctx, user); err != nil {
-			return fmt.Errorf("failed to create or update the user: %w", err)
+		if handled {
+			tracker.markDone(msg)
+		}
+	}
+}
+
+// process handles the message, forwarding it to the dead-letter topic when it
+// can never be handled. It returns false when shutdown cut the retries short
+// and the message is left for redelivery, and an error when a transient failure
+// outlasted the retries.
+func (c *Consumer) process(ctx context.Context, msg kafka.Message) (bool, error) {
+	var (
+		event   events.UserEvent
+		applied bool
+	)
+
+	// The message is handled to the end even if ctx is cancelled meanwhile;
+	// only the pauses between retries are cut short.
+	attempts, err := c.retry.do(ctx, func() error {
+		var (
+			ok  bool
+			err error
+		)
+
+		event, ok, err = c.handle(context.WithoutCancel(ctx), msg)
+		applied = applied || ok
+
+		return err
+	})
+	if err == nil {
+		// A retry after a failed freeze finds the user stored by an earlier
+		// attempt, so the event is stale only if no attempt applied it.
+		if !applied {
+			c.skip(event)
 		}
 
-		logrus.Printf("topic: %s message: %s", msg.Topic, string(msg.Value))
+		return true, nil
 	}
+
+	if ctx.Err() != nil {
+		logrus.Warnf("shutting down before message at offset %d succeeded, it will be redelivered: %v", msg.Offset, err)
+
+		return false, nil
+	}
+
+	if !isPoison(err) {
+		return false, fmt.Errorf("failed to handle message at offset %d after %d attempts: %w", msg.Offset, attempts, err)
+	}
+
+	if err := c.deadLetter(ctx, msg, err, attempts); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (c *Consumer) deadLetter(ctx context.Context, msg kafka.Message, err error, attempts int) error {
+	logrus.Errorf("failed to handle message at offset %d after %d attempts, forwarding to the dead-letter topic: %v",
+		msg.Offset, attempts, err)
+
+	if err := c.dlq.ProduceMessages(ctx, deadLetter(msg, err, attempts)); err != nil {
+		return fmt.Errorf("failed to forward the message to the dead-letter topic: %w", err)
+	}
+
+	return nil
+}
+
+// handle stores the user of the event and reports whether it was applied, as
+// opposed to skipped as stale.
+func (c *Consumer) handle(ctx context.Context, msg kafka.Message) (events.UserEvent, bool, error) {
+	event, err := decode(msg)
+	if err != nil {
+		return events.UserEvent{}, false, err
+	}
+
+	user := event.User()
+
+	applied, err := c.repo.UpsertUser(ctx, user, event.OccurredAt)
+	if err != nil {
+		return event, false, fmt.Errorf("failed to create or update the user: %w", err)
+	}
+
+	if err := c.afterUpsert(ctx, event); err != nil {
+		return event, applied, err
+	}
+
+	logrus.Printf("topic: %s event: %s %s user: %s correlation: %s", msg.Topic, event.Type, event.EventId, user.Id,
+		header(msg, events.HeaderCorrelationId))
+
+	return event, applied, nil
+}
+
+func decode(msg kafka.Message) (events.UserEvent, error) {
+	event, err := events.DecodeUserEvent(msg.Value, contentType(msg))
+	if err != nil {
+		return events.UserEvent{}, poison(fmt.Errorf("failed to decode the user event: %w", err))
+	}
+
+	return event, nil
+}
+
+// afterUpsert freezes the wallets of a deleted user.
+func (c *Consumer) afterUpsert(ctx context.Context, event events.UserEvent) error {
+	user := event.User()
+
+	// Freezing is idempotent, so it also runs for a skipped event: a retry after
+	// the user was stored but the freeze failed must still freeze the wallets.
+	if user.DeletedAt != nil {
+		frozen, err := c.walletRepo.FreezeUserWallets(ctx, user.Id)
+		if err != nil {
+			return fmt.Errorf("failed to freeze the user wallets: %w", err)
+		}
+
+		logrus.Infof("user %s deleted, %d wallets frozen for closure", user.Id, frozen)
+	}
+
+	return nil
+}
+
+// skip reports an event that was not written because a later event of the same
+// user is already stored or supersedes it in the same batch.
+func (c *Consumer) skip(event events.UserEvent) {
+	logrus.Warnf("skipped stale event %s %s for user %s occurred at %s (%d skipped in total)",
+		event.Type, event.EventId, event.Payload.Id, event.OccurredAt.Format(time.RFC3339Nano), c.skipped.Add(1))
+}
+
+// SkippedEvents returns the number of stale events ignored since the consumer
+// was created.
+func (c *Consumer) SkippedEvents() int64 {
+	return c.skipped.Load()
+}
+
+func contentType(msg kafka.Message) string {
+	if value := header(msg, events.HeaderContentType); value != "" {
+		return value
+	}
+
+	return events.ContentTypeJSON
+}
+
+// header returns the value of the header, or "" if the message has none.
+func header(msg kafka.Message, key string) string {
+	for _, header := range msg.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+
+	return ""
+}
+
+func (c *Consumer) commit(ctx context.Context, msgs []kafka.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	if err := c.kf.CommitMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("failed to commit offsets: %w", err)
+	}
+
+	return nil
 }
 
 func (c *Consumer) Close() error {
@@ -0,0 +1,248 @@
+package consumer
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/events"
+)
+
+// fakeUsers records the users it stores. Its first write blocks until release
+// is closed, so that a test can cancel the consumer while it is in flight.
+// Like the repository, it does not apply a user it already stored, and
+// UpsertUsers also reports the users in stale as not applied. The first
+// failures writes fail with err, or all of them if failures is negative.
+type fakeUsers struct {
+	mu       sync.Mutex
+	writes   int
+	users    []uuid.UUID
+	changes  []domain.UserChange
+	stale    []uuid.UUID
+	failures int
+	err      error
+	started  chan struct{}
+	release  chan struct{}
+}
+
+func newFakeUsers() *fakeUsers {
+	return &fakeUsers{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+}
+
+// write stores the users and returns the ones it had not stored before.
+func (u *fakeUsers) write(ids ...uuid.UUID) ([]uuid.UUID, error) {
+	u.mu.Lock()
+	u.writes++
+	first := u.writes == 1
+	failed := u.failures < 0 || u.writes <= u.failures
+	u.mu.Unlock()
+
+	if first {
+		close(u.started)
+		<-u.release
+	}
+
+	if failed {
+		return nil, u.err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var added []uuid.UUID
+
+	for _, id := range ids {
+		if !slices.Contains(u.users, id) {
+			added = append(added, id)
+		}
+	}
+
+	u.users = append(u.users, added...)
+
+	return added, nil
+}
+
+func (u *fakeUsers) UpsertUser(_ context.Context, user domain.User, _ time.Time) (bool, error) {
+	added, err := u.write(user.Id)
+	if err != nil {
+		return false, err
+	}
+
+	return len(added) == 1, nil
+}
+
+func (u *fakeUsers) UpsertUsers(_ context.Context, changes []domain.UserChange) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(changes))
+	for _, change := range changes {
+		ids = append(ids, change.User.Id)
+	}
+
+	added, err := u.write(ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.changes = append(u.changes, changes...)
+
+	applied := make([]uuid.UUID, 0, len(added))
+
+	for _, id := range added {
+		if !slices.Contains(u.stale, id) {
+			applied = append(applied, id)
+		}
+	}
+
+	return applied, nil
+}
+
+func (u *fakeUsers) GetUser(context.Context, uuid.UUID) (domain.User, error) {
+	return domain.User{}, domain.ErrUserNotFound
+}
+
+func (u *fakeUsers) stored() []uuid.UUID {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return append([]uuid.UUID(nil), u.users...)
+}
+
+// fakeWallets fails its first failures freezes with err.
+type fakeWallets struct {
+	mu       sync.Mutex
+	calls    int
+	failures int
+	err      error
+}
+
+func (w *fakeWallets) FreezeUserWallets(context.Context, uuid.UUID) (int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.calls++
+	if w.calls <= w.failures {
+		return 0, w.err
+	}
+
+	return 1, nil
+}
+
+func userMessage(t *testing.T, offset int64, user domain.User) kafka.Message {
+	t.Helper()
+
+	return eventMessage(t, offset, events.NewUserEvent(events.UserCreated, user))
+}
+
+func eventMessage(t *testing.T, offset int64, event events.UserEvent) kafka.Message {
+	t.Helper()
+
+	value, err := events.Encode(event, events.ContentTypeJSON)
+	require.NoError(t, err)
+
+	return kafka.Message{Topic: "users", Offset: offset, Key: []byte(event.Payload.Id.String()), Value: value}
+}
+
+func TestConsumeShutdownFinishesInFlightMessages(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+	}{
+		{name: "one by one", batchSize: 1},
+		{name: "batches", batchSize: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var users []domain.User
+
+			source := &fakeReader{}
+
+			for offset := range int64(4) {
+				user := domain.User{Id: uuid.New()}
+				users = append(users, user)
+				source.messages = append(source.messages, userMessage(t, offset, user))
+			}
+
+			repo := newFakeUsers()
+
+			c := &Consumer{
+				kf:              source,
+				repo:            repo,
+				walletRepo:      &fakeWallets{},
+				dlq:             &fakePublisher{},
+				retry:           retryPolicy{maxAttempts: 1},
+				commitBatchSize: 100,
+				commitInterval:  time.Hour,
+				concurrency:     1,
+				batchSize:       tt.batchSize,
+				batchTimeout:    time.Hour,
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			done := make(chan error, 1)
+
+			go func() {
+				done <- c.Consume(ctx)
+			}()
+
+			<-repo.started
+			cancel()
+
+			// The consumer must wait for the write in flight instead of returning.
+			select {
+			case err := <-done:
+				t.Fatalf("Consume returned before the in-flight write finished: %v", err)
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			close(repo.release)
+
+			select {
+			case err := <-done:
+				require.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("Consume did not return after cancellation")
+			}
+
+			inFlight := int64(tt.batchSize)
+
+			require.ElementsMatch(t, ids(users[:inFlight]), repo.stored())
+			require.Equal(t, map[int]int64{0: inFlight - 1}, source.committedOffsets(),
+				"the in-flight messages are committed, the queued ones are left for redelivery")
+		})
+	}
+}
+
+func ids(users []domain.User) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.Id)
+	}
+
+	return ids
+}
+
+func TestMessageHeaders(t *testing.T) {
+	msg := kafka.Message{Headers: []kafka.Header{
+		{Key: events.HeaderCorrelationId, Value: []byte("request-1")},
+		{Key: events.HeaderContentType, Value: []byte(events.ContentTypeProtobuf)},
+	}}
+
+	require.Equal(t, "request-1", header(msg, events.HeaderCorrelationId))
+	require.Equal(t, events.ContentTypeProtobuf, contentType(msg))
+	require.Empty(t, header(kafka.Message{}, events.HeaderCorrelationId))
+	require.Equal(t, events.ContentTypeJSON, contentType(kafka.Message{}), "a message without the header is JSON")
+}
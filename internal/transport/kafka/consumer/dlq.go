@@ -0,0 +1,154 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	configs "wallet-service/internal/config"
+)
+
+// Headers added to a message forwarded to the dead-letter topic.
+const (
+	HeaderDLQPrefix            = "dlq-"
+	HeaderDLQOriginalTopic     = "dlq-original-topic"
+	HeaderDLQOriginalPartition = "dlq-original-partition"
+	HeaderDLQOriginalOffset    = "dlq-original-offset"
+	HeaderDLQError             = "dlq-error"
+	HeaderDLQErrorClass        = "dlq-error-class"
+	HeaderDLQAttempts          = "dlq-attempts"
+	HeaderDLQFailedAt          = "dlq-failed-at"
+)
+
+const (
+	errorClassPoison    = "poison"
+	errorClassTransient = "transient"
+)
+
+type publisher interface {
+	ProduceMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+func deadLetter(msg kafka.Message, err error, attempts int) kafka.Message {
+	errorClass := errorClassTransient
+	if isPoison(err) {
+		errorClass = errorClassPoison
+	}
+
+	headers := append(withoutDLQHeaders(msg.Headers),
+		kafka.Header{Key: HeaderDLQOriginalTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderDLQOriginalPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderDLQOriginalOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderDLQError, Value: []byte(err.Error())},
+		kafka.Header{Key: HeaderDLQErrorClass, Value: []byte(errorClass)},
+		kafka.Header{Key: HeaderDLQAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: HeaderDLQFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+
+	return kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+func withoutDLQHeaders(headers []kafka.Header) []kafka.Header {
+	kept := make([]kafka.Header, 0, len(headers))
+
+	for _, header := range headers {
+		if !strings.HasPrefix(header.Key, HeaderDLQPrefix) {
+			kept = append(kept, header)
+		}
+	}
+
+	return kept
+}
+
+// Replayer moves messages from the dead-letter topic back to the topic they
+// were consumed from, stripping the dead-letter headers. It stops once the
+// dead-letter topic has been idle for the configured timeout.
+//
+// The target must not have a topic of its own: every replayed message names
+// its topic, as kafka-go rejects a message topic on a writer with a topic.
+type Replayer struct {
+	kf          reader
+	target      publisher
+	topic       string
+	idleTimeout time.Duration
+}
+
+func NewReplayer(cfg *configs.Config, target publisher) *Replayer {
+	kf := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: cfg.Kafka.Brokers,
+		GroupID: cfg.Kafka.DLQReplayGroupID,
+		Topic:   cfg.Kafka.DLQTopic,
+	})
+
+	return &Replayer{
+		kf:          kf,
+		target:      target,
+		topic:       cfg.Kafka.Topic,
+		idleTimeout: cfg.Kafka.DLQReplayIdleTimeout,
+	}
+}
+
+// originalTopic is the topic a dead-lettered message was consumed from, or the
+// configured topic for a message without the header.
+func (r *Replayer) originalTopic(msg kafka.Message) string {
+	for _, header := range msg.Headers {
+		if header.Key == HeaderDLQOriginalTopic && len(header.Value) > 0 {
+			return string(header.Value)
+		}
+	}
+
+	return r.topic
+}
+
+func (r *Replayer) Replay(ctx context.Context) (int, error) {
+	replayed := 0
+
+	for {
+		fetchCtx, cancel := context.WithTimeout(ctx, r.idleTimeout)
+		msg, err := r.kf.FetchMessage(fetchCtx)
+		cancel()
+
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				return replayed, nil
+			}
+
+			return replayed, fmt.Errorf("failed to consume a dead-lettered message: %w", err)
+		}
+
+		err = r.target.ProduceMessages(ctx, kafka.Message{
+			Topic:   r.originalTopic(msg),
+			Key:     msg.Key,
+			Value:   msg.Value,
+			Headers: withoutDLQHeaders(msg.Headers),
+		})
+		if err != nil {
+			return replayed, fmt.Errorf("failed to replay a dead-lettered message: %w", err)
+		}
+
+		if err := r.kf.CommitMessages(ctx, msg); err != nil {
+			return replayed, fmt.Errorf("failed to commit offsets: %w", err)
+		}
+
+		replayed++
+
+		logrus.Infof("replayed message at offset %d of %s to %s", msg.Offset, msg.Topic, r.originalTopic(msg))
+	}
+}
+
+func (r *Replayer) Close() error {
+	if err := r.kf.Close(); err != nil {
+		return fmt.Errorf("failed to close Kafka consumer: %w", err)
+	}
+
+	return nil
+}
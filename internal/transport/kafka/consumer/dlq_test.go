@@ -0,0 +1,196 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"wallet-service/internal/domain"
+)
+
+func headers(msg kafka.Message) map[string]string {
+	values := make(map[string]string, len(msg.Headers))
+
+	for _, header := range msg.Headers {
+		values[header.Key] = string(header.Value)
+	}
+
+	return values
+}
+
+func TestDeadLetterHeaders(t *testing.T) {
+	msg := kafka.Message{
+		Topic:     "users",
+		Partition: 2,
+		Offset:    41,
+		Key:       []byte("user-1"),
+		Value:     []byte(`{"broken"`),
+		Headers: []kafka.Header{
+			{Key: "content-type", Value: []byte("application/json")},
+			// Headers of an earlier dead-lettering are replaced, not repeated.
+			{Key: HeaderDLQAttempts, Value: []byte("1")},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		errorClass string
+	}{
+		{name: "poison", err: poison(errors.New("malformed JSON")), errorClass: errorClassPoison},
+		{name: "transient", err: errors.New("connection reset"), errorClass: errorClassTransient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dead := deadLetter(msg, tt.err, 5)
+
+			require.Empty(t, dead.Topic, "the producer picks the dead-letter topic")
+			require.Equal(t, msg.Key, dead.Key)
+			require.Equal(t, msg.Value, dead.Value)
+			require.Len(t, dead.Headers, 8)
+
+			values := headers(dead)
+			require.Equal(t, "application/json", values["content-type"])
+			require.Equal(t, "users", values[HeaderDLQOriginalTopic])
+			require.Equal(t, "2", values[HeaderDLQOriginalPartition])
+			require.Equal(t, "41", values[HeaderDLQOriginalOffset])
+			require.Equal(t, tt.err.Error(), values[HeaderDLQError])
+			require.Equal(t, tt.errorClass, values[HeaderDLQErrorClass])
+			require.Equal(t, "5", values[HeaderDLQAttempts])
+
+			_, err := time.Parse(time.RFC3339, values[HeaderDLQFailedAt])
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConsumerDeadLettersPoison(t *testing.T) {
+	dlq := &fakePublisher{}
+	c := &Consumer{
+		dlq:   dlq,
+		retry: retryPolicy{maxAttempts: 3},
+	}
+
+	msg := kafka.Message{Topic: "users", Partition: 1, Offset: 7, Value: []byte("not json")}
+
+	handled, err := c.process(context.Background(), msg)
+	require.NoError(t, err)
+	require.True(t, handled)
+
+	produced := dlq.produced()
+	require.Len(t, produced, 1)
+
+	values := headers(produced[0])
+	require.Equal(t, "users", values[HeaderDLQOriginalTopic])
+	require.Equal(t, "1", values[HeaderDLQOriginalPartition])
+	require.Equal(t, "7", values[HeaderDLQOriginalOffset])
+	require.Equal(t, errorClassPoison, values[HeaderDLQErrorClass])
+	require.Equal(t, "1", values[HeaderDLQAttempts])
+}
+
+func TestConsumerStopsOnTransientFailure(t *testing.T) {
+	errOutage := errors.New("connection refused")
+
+	for _, batchSize := range []int{1, 2} {
+		t.Run(fmt.Sprintf("batch size %d", batchSize), func(t *testing.T) {
+			source := &fakeReader{}
+
+			for offset := range int64(4) {
+				source.messages = append(source.messages, userMessage(t, offset, domain.User{Id: uuid.New()}))
+			}
+
+			repo := newFakeUsers()
+			repo.failures = -1
+			repo.err = errOutage
+			close(repo.release)
+
+			dlq := &fakePublisher{}
+
+			c := &Consumer{
+				kf:              source,
+				repo:            repo,
+				walletRepo:      &fakeWallets{},
+				dlq:             dlq,
+				retry:           retryPolicy{maxAttempts: 3, initialBackoff: time.Millisecond, maxBackoff: time.Millisecond},
+				commitBatchSize: 1,
+				commitInterval:  time.Hour,
+				concurrency:     1,
+				batchSize:       batchSize,
+				batchTimeout:    time.Millisecond,
+			}
+
+			done := make(chan error, 1)
+
+			go func() {
+				done <- c.Consume(context.Background())
+			}()
+
+			select {
+			case err := <-done:
+				require.ErrorIs(t, err, errOutage)
+			case <-time.After(5 * time.Second):
+				t.Fatal("Consume did not stop on a transient failure")
+			}
+
+			require.Empty(t, dlq.produced(), "a transient failure is never dead-lettered")
+			require.Empty(t, source.committed, "the failed message is left for redelivery")
+		})
+	}
+}
+
+func TestReplayerRepublishesToOriginalTopic(t *testing.T) {
+	dead := func(topic string, offset int64) kafka.Message {
+		msg := deadLetter(kafka.Message{Topic: topic, Offset: offset, Key: []byte("user-1"), Value: []byte("{}"),
+			Headers: []kafka.Header{{Key: "content-type", Value: []byte("application/json")}},
+		}, errors.New("connection reset"), 5)
+		msg.Topic = "users-dlq"
+
+		return msg
+	}
+
+	withoutHeader := kafka.Message{Topic: "users-dlq", Value: []byte("{}")}
+
+	source := &fakeReader{messages: []kafka.Message{dead("users", 1), dead("users-v2", 2), withoutHeader}}
+	target := &fakePublisher{}
+
+	replayer := &Replayer{
+		kf:          source,
+		target:      target,
+		topic:       "users",
+		idleTimeout: 10 * time.Millisecond,
+	}
+
+	replayed, err := replayer.Replay(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, replayed)
+
+	produced := target.produced()
+	require.Len(t, produced, 3)
+	require.Equal(t, "users", produced[0].Topic)
+	require.Equal(t, "users-v2", produced[1].Topic)
+	require.Equal(t, "users", produced[2].Topic, "a message without the header goes to the configured topic")
+
+	for _, msg := range produced[:2] {
+		require.Equal(t, []kafka.Header{{Key: "content-type", Value: []byte("application/json")}}, msg.Headers)
+	}
+
+	require.Len(t, source.committed, 3)
+}
+
+func TestReplayerStopsOnPublishFailure(t *testing.T) {
+	source := &fakeReader{messages: []kafka.Message{{Topic: "users-dlq", Value: []byte("{}")}}}
+	target := &fakePublisher{err: errors.New("broker unavailable")}
+
+	replayer := &Replayer{kf: source, target: target, topic: "users", idleTimeout: 10 * time.Millisecond}
+
+	replayed, err := replayer.Replay(context.Background())
+	require.Error(t, err)
+	require.Zero(t, replayed)
+	require.Empty(t, source.committed, "a message that was not replayed stays on the dead-letter topic")
+}
@@ -0,0 +1,95 @@
+package consumer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeReader hands out its messages in order and then blocks like an idle
+// topic until the fetch context ends.
+type fakeReader struct {
+	mu        sync.Mutex
+	messages  []kafka.Message
+	committed []kafka.Message
+	closed    bool
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.mu.Lock()
+
+	if len(r.messages) > 0 {
+		msg := r.messages[0]
+		r.messages = r.messages[1:]
+		r.mu.Unlock()
+
+		return msg, nil
+	}
+
+	r.mu.Unlock()
+
+	<-ctx.Done()
+
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.committed = append(r.committed, msgs...)
+
+	return nil
+}
+
+func (r *fakeReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+
+	return nil
+}
+
+// committedOffsets returns the highest committed offset of every partition.
+func (r *fakeReader) committedOffsets() map[int]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	offsets := make(map[int]int64)
+
+	for _, msg := range r.committed {
+		if offset, ok := offsets[msg.Partition]; !ok || msg.Offset > offset {
+			offsets[msg.Partition] = msg.Offset
+		}
+	}
+
+	return offsets
+}
+
+type fakePublisher struct {
+	mu       sync.Mutex
+	messages []kafka.Message
+	err      error
+}
+
+func (p *fakePublisher) ProduceMessages(_ context.Context, msgs ...kafka.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	p.messages = append(p.messages, msgs...)
+
+	return nil
+}
+
+func (p *fakePublisher) produced() []kafka.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]kafka.Message(nil), p.messages...)
+}
@@ -0,0 +1,100 @@
+package consumer
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// offsetTracker works out which offsets are safe to commit while messages of a
+// partition finish out of order. Committing an offset acknowledges every
+// message before it, so a partition's commit point only advances past a
+// message once all messages fetched before it are done as well.
+type offsetTracker struct {
+	mu              sync.Mutex
+	partitions      map[int]*partitionOffsets
+	doneSinceCommit int
+}
+
+type partitionOffsets struct {
+	// pending holds the messages fetched but not yet committable, in fetch order.
+	pending []kafka.Message
+	done    map[int64]bool
+	// committable is the last message up to which every message is done.
+	committable *kafka.Message
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{
+		partitions: make(map[int]*partitionOffsets),
+	}
+}
+
+// add registers a fetched message. Messages of a partition must be added in
+// the order they were fetched.
+func (t *offsetTracker) add(msg kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	partition, ok := t.partitions[msg.Partition]
+	if !ok {
+		partition = &partitionOffsets{
+			done: make(map[int64]bool),
+		}
+		t.partitions[msg.Partition] = partition
+	}
+
+	partition.pending = append(partition.pending, msg)
+}
+
+func (t *offsetTracker) markDone(msg kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	partition := t.partitions[msg.Partition]
+	partition.done[msg.Offset] = true
+	t.doneSinceCommit++
+
+	advanced := 0
+
+	for _, pending := range partition.pending {
+		if !partition.done[pending.Offset] {
+			break
+		}
+
+		partition.committable = &pending
+		delete(partition.done, pending.Offset)
+
+		advanced++
+	}
+
+	partition.pending = slices.Delete(partition.pending, 0, advanced)
+}
+
+// takeCommittable returns the messages to commit, one per partition whose
+// commit point advanced since the last call.
+func (t *offsetTracker) takeCommittable() []kafka.Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var msgs []kafka.Message
+
+	for _, partition := range t.partitions {
+		if partition.committable != nil {
+			msgs = append(msgs, *partition.committable)
+			partition.committable = nil
+		}
+	}
+
+	t.doneSinceCommit = 0
+
+	return msgs
+}
+
+func (t *offsetTracker) uncommitted() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.doneSinceCommit
+}
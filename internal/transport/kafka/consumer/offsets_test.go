@@ -0,0 +1,105 @@
+package consumer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+func message(partition int, offset int64) kafka.Message {
+	return kafka.Message{Partition: partition, Offset: offset}
+}
+
+// committed returns the committable offset of every partition.
+func committed(tracker *offsetTracker) map[int]int64 {
+	offsets := make(map[int]int64)
+
+	for _, msg := range tracker.takeCommittable() {
+		offsets[msg.Partition] = msg.Offset
+	}
+
+	return offsets
+}
+
+func TestOffsetTrackerOutOfOrder(t *testing.T) {
+	tracker := newOffsetTracker()
+
+	for offset := range int64(4) {
+		tracker.add(message(0, offset))
+	}
+
+	tracker.markDone(message(0, 2))
+	tracker.markDone(message(0, 1))
+	require.Empty(t, committed(tracker), "offset 0 is still in flight")
+
+	tracker.markDone(message(0, 0))
+	require.Equal(t, map[int]int64{0: 2}, committed(tracker))
+
+	require.Empty(t, committed(tracker), "nothing advanced since the last commit")
+
+	tracker.markDone(message(0, 3))
+	require.Equal(t, map[int]int64{0: 3}, committed(tracker))
+}
+
+func TestOffsetTrackerLowWaterMark(t *testing.T) {
+	tests := []struct {
+		name string
+		done []int64
+		want map[int]int64
+	}{
+		{name: "nothing done", done: nil, want: map[int]int64{}},
+		{name: "first done", done: []int64{10}, want: map[int]int64{0: 10}},
+		{name: "gap after the first", done: []int64{10, 12, 13}, want: map[int]int64{0: 10}},
+		{name: "gap at the start", done: []int64{11, 12, 13}, want: map[int]int64{}},
+		{name: "contiguous prefix", done: []int64{12, 11, 10}, want: map[int]int64{0: 12}},
+		{name: "all done", done: []int64{13, 10, 12, 11}, want: map[int]int64{0: 13}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newOffsetTracker()
+
+			for offset := int64(10); offset < 14; offset++ {
+				tracker.add(message(0, offset))
+			}
+
+			for _, offset := range tt.done {
+				tracker.markDone(message(0, offset))
+			}
+
+			require.Equal(t, len(tt.done), tracker.uncommitted())
+			require.Equal(t, tt.want, committed(tracker))
+			require.Zero(t, tracker.uncommitted())
+		})
+	}
+}
+
+func TestOffsetTrackerPartitions(t *testing.T) {
+	tracker := newOffsetTracker()
+
+	for offset := range int64(3) {
+		tracker.add(message(0, offset))
+		tracker.add(message(1, offset))
+		tracker.add(message(2, offset))
+	}
+
+	// A message stuck on partition 0 must not hold back the other partitions.
+	tracker.markDone(message(0, 1))
+	tracker.markDone(message(1, 0))
+	tracker.markDone(message(1, 1))
+	tracker.markDone(message(2, 0))
+	tracker.markDone(message(2, 1))
+	tracker.markDone(message(2, 2))
+
+	msgs := tracker.takeCommittable()
+	slices.SortFunc(msgs, func(a, b kafka.Message) int {
+		return a.Partition - b.Partition
+	})
+
+	require.Equal(t, []kafka.Message{message(1, 1), message(2, 2)}, msgs)
+
+	tracker.markDone(message(0, 0))
+	require.Equal(t, map[int]int64{0: 1}, committed(tracker))
+}
@@ -0,0 +1,87 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	configs "wallet-service/internal/config"
+	"wallet-service/internal/domain"
+)
+
+// poisonError marks a message that fails the same way however often it is
+// retried, such as malformed JSON.
+type poisonError struct {
+	err error
+}
+
+func (e *poisonError) Error() string {
+	return e.err.Error()
+}
+
+func (e *poisonError) Unwrap() error {
+	return e.err
+}
+
+func poison(err error) error {
+	return &poisonError{err: err}
+}
+
+func isPoison(err error) bool {
+	var poisonErr *poisonError
+	if errors.As(err, &poisonErr) {
+		return true
+	}
+
+	var domainErr *domain.Error
+
+	return errors.As(err, &domainErr) && domainErr.Kind == domain.KindValidation
+}
+
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+func newRetryPolicy(cfg configs.KafkaConfig) retryPolicy {
+	return retryPolicy{
+		maxAttempts:    max(cfg.RetryMaxAttempts, 1),
+		initialBackoff: cfg.RetryInitialBackoff,
+		maxBackoff:     cfg.RetryMaxBackoff,
+	}
+}
+
+// do calls fn until it succeeds, fails with a poison error or runs out of
+// attempts, pausing between attempts as backoff says. It returns the last error
+// and the number of attempts made.
+func (p retryPolicy) do(ctx context.Context, fn func() error) (int, error) {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || isPoison(err) || attempt >= p.maxAttempts {
+			return attempt, err
+		}
+
+		select {
+		case <-time.After(p.backoff(attempt)):
+		case <-ctx.Done():
+			return attempt, errors.Join(err, ctx.Err())
+		}
+	}
+}
+
+// backoff is the pause after the given failed attempt: initialBackoff, doubled
+// with every further attempt up to maxBackoff.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.initialBackoff
+
+	for range attempt - 1 {
+		if backoff >= p.maxBackoff {
+			break
+		}
+
+		backoff *= 2
+	}
+
+	return min(backoff, p.maxBackoff)
+}
@@ -0,0 +1,122 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	configs "wallet-service/internal/config"
+	"wallet-service/internal/domain"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := retryPolicy{
+		maxAttempts:    10,
+		initialBackoff: 100 * time.Millisecond,
+		maxBackoff:     time.Second,
+	}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 3, want: 400 * time.Millisecond},
+		{attempt: 4, want: 800 * time.Millisecond},
+		{attempt: 5, want: time.Second},
+		{attempt: 9, want: time.Second},
+		{attempt: 200, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tt.attempt), func(t *testing.T) {
+			require.Equal(t, tt.want, policy.backoff(tt.attempt))
+		})
+	}
+}
+
+func TestRetryPolicyAttempts(t *testing.T) {
+	errTemporary := errors.New("connection reset")
+
+	tests := []struct {
+		name         string
+		maxAttempts  int
+		failures     int
+		err          error
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "first attempt succeeds", maxAttempts: 3, failures: 0, wantAttempts: 1},
+		{name: "retry succeeds", maxAttempts: 3, failures: 2, err: errTemporary, wantAttempts: 3},
+		{name: "attempts exhausted", maxAttempts: 3, failures: 5, err: errTemporary, wantAttempts: 3, wantErr: errTemporary},
+		{name: "no retries configured", maxAttempts: 0, failures: 5, err: errTemporary, wantAttempts: 1, wantErr: errTemporary},
+		{name: "poison is not retried", maxAttempts: 3, failures: 5, err: poison(errTemporary), wantAttempts: 1, wantErr: errTemporary},
+		{name: "validation error is not retried", maxAttempts: 3, failures: 5, err: domain.ErrInvalidAmount, wantAttempts: 1,
+			wantErr: domain.ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := newRetryPolicy(configs.KafkaConfig{RetryMaxAttempts: tt.maxAttempts})
+
+			calls := 0
+
+			attempts, err := policy.do(context.Background(), func() error {
+				calls++
+				if calls <= tt.failures {
+					return tt.err
+				}
+
+				return nil
+			})
+
+			require.Equal(t, tt.wantAttempts, attempts)
+			require.Equal(t, tt.wantAttempts, calls)
+
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyCancel(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 5, initialBackoff: time.Hour, maxBackoff: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts, err := policy.do(ctx, func() error {
+		return errors.New("connection reset")
+	})
+
+	require.Equal(t, 1, attempts)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestIsPoison(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "plain error", err: errors.New("connection reset"), want: false},
+		{name: "poison", err: poison(errors.New("malformed JSON")), want: true},
+		{name: "wrapped poison", err: fmt.Errorf("handle: %w", poison(errors.New("malformed JSON"))), want: true},
+		{name: "validation error", err: fmt.Errorf("upsert: %w", domain.ErrInvalidAmount), want: true},
+		{name: "not found error", err: domain.ErrUserNotFound, want: false},
+		{name: "deadline", err: context.DeadlineExceeded, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, isPoison(tt.err))
+		})
+	}
+}
@@ -3,33 +3,198 @@ package producer
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
 	configs "wallet-service/internal/config"
+	"wallet-service/internal/events"
 )
 
 type Producer struct {
-	producer *kafka.Writer
+	producer    *kafka.Writer
+	contentType string
 }
 
-func New(cfg *configs.Config) *Producer {
-	producer := kafka.NewWriter(kafka.WriterConfig{
-		Brokers: cfg.Kafka.Brokers,
-		Topic:   cfg.Kafka.Topic,
-	})
+// Option overrides a setting of the producer taken from KafkaConfig.
+type Option func(w *kafka.Writer)
+
+// WithTopic makes the producer write to topic instead of the configured one.
+func WithTopic(topic string) Option {
+	return func(w *kafka.Writer) {
+		w.Topic = topic
+	}
+}
+
+// WithBalancer makes the producer pick partitions with balancer.
+func WithBalancer(balancer kafka.Balancer) Option {
+	return func(w *kafka.Writer) {
+		w.Balancer = balancer
+	}
+}
+
+// WithSync makes every write wait until all in-sync replicas have the
+// messages, whatever the configuration says. Callers that treat a returned
+// Produce as delivered, like the outbox relay, need it.
+func WithSync() Option {
+	return func(w *kafka.Writer) {
+		w.Async = false
+		w.RequiredAcks = kafka.RequireAll
+	}
+}
+
+func New(cfg *configs.Config, opts ...Option) (*Producer, error) {
+	balancer, err := parseBalancer(cfg.Kafka.ProducerBalancer)
+	if err != nil {
+		return nil, err
+	}
+
+	compression, err := parseCompression(cfg.Kafka.ProducerCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	requiredAcks, err := parseRequiredAcks(cfg.Kafka.ProducerRequiredAcks)
+	if err != nil {
+		return nil, err
+	}
+
+	producer := &kafka.Writer{
+		Addr:                   kafka.TCP(cfg.Kafka.Brokers...),
+		Topic:                  cfg.Kafka.Topic,
+		Balancer:               balancer,
+		Compression:            compression,
+		RequiredAcks:           requiredAcks,
+		Async:                  cfg.Kafka.ProducerAsync,
+		BatchSize:              cfg.Kafka.ProducerBatchSize,
+		BatchTimeout:           cfg.Kafka.ProducerBatchTimeout,
+		AllowAutoTopicCreation: true,
+	}
+
+	for _, opt := range opts {
+		opt(producer)
+	}
+
+	if producer.Async {
+		// An asynchronous write returns before the brokers answer, so failures
+		// can only be reported here.
+		producer.Completion = func(messages []kafka.Message, err error) {
+			if err != nil {
+				logrus.Errorf("failed to produce %d messages to %s: %v", len(messages), producer.Topic, err)
+			}
+		}
+	}
 
 	return &Producer{
-		producer: producer,
+		producer:    producer,
+		contentType: cfg.Kafka.ContentType,
+	}, nil
+}
+
+// Message is an event to produce. The producer encodes the event with the
+// codec of the configured content type.
+type Message struct {
+	Event   any
+	Key     string
+	Headers []kafka.Header
+}
+
+// MessageOption sets the key or a header of a produced message.
+type MessageOption func(msg *Message)
+
+// WithKey sets the message key. Messages with the same key land on the same
+// partition in the order they were produced, given the hash balancer.
+func WithKey(key string) MessageOption {
+	return func(msg *Message) {
+		msg.Key = key
+	}
+}
+
+func WithHeader(key, value string) MessageOption {
+	return func(msg *Message) {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: key, Value: []byte(value)})
 	}
 }
 
-func (p *Producer) Produce(cfg *configs.Config, ctx context.Context, value []byte) error {
-	err := p.producer.WriteMessages(ctx, kafka.Message{
-		Value: value,
-		Time:  time.Now(),
-	})
+func WithEventType(eventType string) MessageOption {
+	return WithHeader(events.HeaderEventType, eventType)
+}
+
+func WithSchemaVersion(version int) MessageOption {
+	return WithHeader(events.HeaderSchemaVersion, strconv.Itoa(version))
+}
+
+func WithCorrelationId(correlationId string) MessageOption {
+	return WithHeader(events.HeaderCorrelationId, correlationId)
+}
+
+func NewMessage(event any, opts ...MessageOption) Message {
+	msg := Message{
+		Event: event,
+	}
+
+	for _, opt := range opts {
+		opt(&msg)
+	}
+
+	return msg
+}
+
+func (p *Producer) Produce(ctx context.Context, event any, opts ...MessageOption) error {
+	return p.ProduceBatch(ctx, NewMessage(event, opts...))
+}
+
+// ProduceBatch encodes the events and writes them in a single call, recording
+// the content type in the headers of every message. A message without a
+// correlation id gets a new one, so that every message can be traced.
+func (p *Producer) ProduceBatch(ctx context.Context, msgs ...Message) error {
+	kafkaMsgs, err := p.encode(msgs)
 	if err != nil {
+		return err
+	}
+
+	return p.ProduceMessages(ctx, kafkaMsgs...)
+}
+
+func (p *Producer) encode(msgs []Message) ([]kafka.Message, error) {
+	kafkaMsgs := make([]kafka.Message, 0, len(msgs))
+
+	for _, msg := range msgs {
+		value, err := events.Encode(msg.Event, p.contentType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to produce a messages: %w", err)
+		}
+
+		headers := append(slices.Clone(msg.Headers), kafka.Header{Key: events.HeaderContentType, Value: []byte(p.contentType)})
+
+		if !slices.ContainsFunc(headers, func(header kafka.Header) bool {
+			return header.Key == events.HeaderCorrelationId
+		}) {
+			headers = append(headers, kafka.Header{Key: events.HeaderCorrelationId, Value: []byte(uuid.NewString())})
+		}
+
+		kafkaMsg := kafka.Message{
+			Value:   value,
+			Time:    time.Now(),
+			Headers: headers,
+		}
+
+		if msg.Key != "" {
+			kafkaMsg.Key = []byte(msg.Key)
+		}
+
+		kafkaMsgs = append(kafkaMsgs, kafkaMsg)
+	}
+
+	return kafkaMsgs, nil
+}
+
+// ProduceMessages writes already encoded messages as they are.
+func (p *Producer) ProduceMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if err := p.producer.WriteMessages(ctx, msgs...); err != nil {
 		return fmt.Errorf("failed to produce a messages: %w", err)
 	}
 
@@ -43,3 +208,46 @@ func (p *Producer) Close() error {
 
 	return nil
 }
+
+func parseBalancer(name string) (kafka.Balancer, error) {
+	switch name {
+	case "hash":
+		return &kafka.Hash{}, nil
+	case "round-robin":
+		return &kafka.RoundRobin{}, nil
+	case "least-bytes":
+		return &kafka.LeastBytes{}, nil
+	default:
+		return nil, fmt.Errorf("unknown producer balancer %q", name)
+	}
+}
+
+func parseCompression(name string) (kafka.Compression, error) {
+	switch name {
+	case "none", "":
+		return 0, nil
+	case "gzip":
+		return kafka.Gzip, nil
+	case "snappy":
+		return kafka.Snappy, nil
+	case "lz4":
+		return kafka.Lz4, nil
+	case "zstd":
+		return kafka.Zstd, nil
+	default:
+		return 0, fmt.Errorf("unknown producer compression %q", name)
+	}
+}
+
+func parseRequiredAcks(name string) (kafka.RequiredAcks, error) {
+	switch name {
+	case "all":
+		return kafka.RequireAll, nil
+	case "one":
+		return kafka.RequireOne, nil
+	case "none":
+		return kafka.RequireNone, nil
+	default:
+		return 0, fmt.Errorf("unknown producer required acks %q", name)
+	}
+}
@@ -0,0 +1,51 @@
+package producer
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/events"
+)
+
+func correlationIds(msgs []kafka.Message) []string {
+	var ids []string
+
+	for _, msg := range msgs {
+		for _, header := range msg.Headers {
+			if header.Key == events.HeaderCorrelationId {
+				ids = append(ids, string(header.Value))
+			}
+		}
+	}
+
+	return ids
+}
+
+func TestEncodeSetsCorrelationId(t *testing.T) {
+	p := &Producer{contentType: events.ContentTypeJSON}
+	event := events.NewUserEvent(events.UserCreated, domain.User{Id: uuid.New()})
+
+	t.Run("given correlation id is kept", func(t *testing.T) {
+		msgs, err := p.encode([]Message{NewMessage(event, WithCorrelationId("request-1"))})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"request-1"}, correlationIds(msgs))
+	})
+
+	t.Run("missing correlation id is generated", func(t *testing.T) {
+		msgs, err := p.encode([]Message{NewMessage(event), NewMessage(event)})
+		require.NoError(t, err)
+
+		ids := correlationIds(msgs)
+		require.Len(t, ids, 2)
+		require.NotEqual(t, ids[0], ids[1])
+
+		for _, id := range ids {
+			_, err := uuid.Parse(id)
+			require.NoError(t, err)
+		}
+	})
+}
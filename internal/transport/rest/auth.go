@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type contextKey string
+
+const userIdContextKey contextKey = "userId"
+
+type authenticator interface {
+	Authenticate(token string) (uuid.UUID, error)
+}
+
+// authenticate verifies the bearer token and stores its subject in the request
+// context, where handlers read it with getUserId.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer`)
+			writeError(w, http.StatusUnauthorized, codeUnauthorized, "missing bearer token")
+
+			return
+		}
+
+		userId, err := s.authenticator.Authenticate(token)
+		if err != nil {
+			logrus.Debugf("Authentication error: %v\n", err)
+
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeError(w, http.StatusUnauthorized, codeUnauthorized, "invalid bearer token")
+
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userIdContextKey, userId)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
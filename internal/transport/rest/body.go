@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"wallet-service/internal/domain"
+)
+
+// maxBodyBytes bounds every request body; wallet payloads are a few hundred
+// bytes at most.
+const maxBodyBytes = 1 << 16
+
+var ErrTrailingData = errors.New("request body must hold a single JSON value")
+
+// limitBody makes reading a request body beyond maxBodyBytes fail, before any
+// handler or middleware reads it.
+func limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// decodeJSON strictly decodes the request body into dst: fields that dst does
+// not have are rejected instead of ignored, and so is anything after the value.
+// Errors about a single field are reported as field errors.
+func decodeJSON(r *http.Request, dst any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		var typeErr *json.UnmarshalTypeError
+
+		// encoding/json has no error type for unknown fields.
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return domain.NewFieldsError([]domain.FieldError{
+				{Field: strings.Trim(field, `"`), Message: "is not a known field"},
+			})
+		}
+
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return domain.NewFieldsError([]domain.FieldError{
+				{Field: typeErr.Field, Message: "must not be a JSON " + typeErr.Value},
+			})
+		}
+
+		return fmt.Errorf("failed to decode the request body: %w", err)
+	}
+
+	// Reading past the value can still hit the body limit.
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return err
+		}
+
+		return ErrTrailingData
+	}
+
+	return nil
+}
+
+// writeBodyError answers a request whose body could not be read or decoded.
+func writeBodyError(w http.ResponseWriter, err error) {
+	var (
+		tooLarge  *http.MaxBytesError
+		domainErr *domain.Error
+	)
+
+	switch {
+	case errors.As(err, &tooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, codePayloadTooLarge,
+			fmt.Sprintf("request body must not exceed %d bytes", tooLarge.Limit))
+	case errors.As(err, &domainErr):
+		errorResponse(w, err)
+	default:
+		writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+	}
+}
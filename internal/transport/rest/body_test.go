@@ -0,0 +1,159 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"wallet-service/internal/domain"
+)
+
+// createWalletHandler decodes and validates a wallet the way the wallet
+// handlers do, without a service behind it.
+var createWalletHandler = limitBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	var walletInfo domain.WalletInfo
+
+	if err := decodeJSON(r, &walletInfo); err != nil {
+		writeBodyError(w, err)
+
+		return
+	}
+
+	balance := walletInfo.Balance
+	if balance == (domain.Money{}) {
+		balance = domain.NewMoney(0, walletInfo.Currency)
+	}
+
+	wallet := domain.Wallet{Name: walletInfo.Name, Currency: walletInfo.Currency, Balance: balance}
+	if err := wallet.Validate(); err != nil {
+		errorResponse(w, err)
+
+		return
+	}
+
+	response(w, http.StatusCreated, walletInfo)
+}))
+
+func serveBody(t *testing.T, body string) (int, errorDetails) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	createWalletHandler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/wallets", strings.NewReader(body)))
+
+	var result errorBody
+
+	if recorder.Code != http.StatusCreated {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	}
+
+	return recorder.Code, result.Error
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantFields []domain.FieldError
+	}{
+		{
+			name:       "valid",
+			body:       `{"name": "savings", "currency": "USD", "balance": {"amount": "1.00", "currency": "USD"}}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "unknown field",
+			body:       `{"name": "savings", "currency": "USD", "colour": "red"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_fields",
+			wantFields: []domain.FieldError{{Field: "colour", Message: "is not a known field"}},
+		},
+		{
+			name:       "unknown nested field",
+			body:       `{"name": "savings", "currency": "USD", "balance": {"amount": "1.00", "currency": "USD", "rate": 1}}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   codeBadRequest,
+		},
+		{
+			name:       "wrong type",
+			body:       `{"name": 42, "currency": "USD"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_fields",
+			wantFields: []domain.FieldError{{Field: "name", Message: "must not be a JSON number"}},
+		},
+		{
+			name:       "invalid money",
+			body:       `{"name": "savings", "currency": "USD", "balance": {"amount": "1.005", "currency": "USD"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrInvalidMoney.Code,
+		},
+		{
+			name:       "trailing data",
+			body:       `{"name": "savings", "currency": "USD"} {}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   codeBadRequest,
+		},
+		{
+			name:       "malformed",
+			body:       `{"name": "savings",`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   codeBadRequest,
+		},
+		{
+			name:       "empty",
+			body:       ``,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   codeBadRequest,
+		},
+		{
+			name:       "every invalid field is reported",
+			body:       `{"name": " ", "currency": "XXX", "balance": {"amount": "-1.00", "currency": "EUR"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_fields",
+			wantFields: []domain.FieldError{
+				{Field: "name", Message: "must not be empty"},
+				{Field: "currency", Message: "must be an ISO 4217 currency code"},
+				{Field: "balance", Message: "must not be negative"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, result := serveBody(t, tt.body)
+			require.Equal(t, tt.wantStatus, status)
+			require.Equal(t, tt.wantCode, result.Code)
+			require.Equal(t, tt.wantFields, result.Fields)
+		})
+	}
+}
+
+func TestDecodeJSONBodyLimit(t *testing.T) {
+	// Padding with whitespace keeps the body valid at any size.
+	body := func(size int) string {
+		value := `{"name": "savings", "currency": "USD"}`
+
+		return value + strings.Repeat(" ", size-len(value))
+	}
+
+	t.Run("at the limit", func(t *testing.T) {
+		status, _ := serveBody(t, body(maxBodyBytes))
+		require.Equal(t, http.StatusCreated, status)
+	})
+
+	t.Run("above the limit", func(t *testing.T) {
+		status, result := serveBody(t, body(maxBodyBytes+1))
+		require.Equal(t, http.StatusRequestEntityTooLarge, status)
+		require.Equal(t, codePayloadTooLarge, result.Code)
+	})
+
+	t.Run("above the limit inside the value", func(t *testing.T) {
+		status, result := serveBody(t, `{"name": "`+strings.Repeat("a", maxBodyBytes)+`", "currency": "USD"}`)
+		require.Equal(t, http.StatusRequestEntityTooLarge, status)
+		require.Equal(t, codePayloadTooLarge, result.Code)
+	})
+}
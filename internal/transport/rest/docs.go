@@ -0,0 +1,61 @@
+package rest
+
+import (
+	_ "embed"
+	"net/http"
+
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+const (
+	openAPIPath = "/api/v1/openapi.json"
+	docsPath    = "/api/v1/docs/"
+)
+
+// openAPISpec describes every route of InitRoutes; tests fail when a route is
+// missing from it.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// swaggerInitializer replaces the one bundled with Swagger UI, which loads the
+// petstore example instead of our document.
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "` + openAPIPath + `",
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    presets: [
+      SwaggerUIBundle.presets.apis,
+      SwaggerUIStandalonePreset
+    ],
+    plugins: [
+      SwaggerUIBundle.plugins.DownloadUrl
+    ],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+func (h *Server) getOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	_, _ = w.Write(openAPISpec)
+}
+
+// docs serves the Swagger UI bundle under docsPath.
+func docs() http.Handler {
+	files := http.StripPrefix(docsPath, http.FileServer(http.FS(swaggerFiles.FS)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == docsPath+"swagger-initializer.js" {
+			w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+			_, _ = w.Write([]byte(swaggerInitializer))
+
+			return
+		}
+
+		files.ServeHTTP(w, r)
+	})
+}
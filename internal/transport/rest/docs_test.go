@@ -0,0 +1,191 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	"wallet-service/internal/transport/rest"
+)
+
+const openAPIPath = "/api/v1/openapi.json"
+
+type openAPISpec struct {
+	OpenAPI    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+		RequestBodies map[string]struct {
+			Content map[string]struct {
+				Schema struct {
+					Ref string `json:"$ref"`
+				} `json:"schema"`
+			} `json:"content"`
+		} `json:"requestBodies"`
+	} `json:"components"`
+}
+
+// routes builds the router with stub services: the handlers are never called.
+func routes() *mux.Router {
+	return rest.New(service.New(nil, nil), nil, nil, 0).InitRoutes()
+}
+
+func get(t *testing.T, router http.Handler, path string) (int, []byte) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	return rec.Code, body
+}
+
+func loadSpec(t *testing.T, router http.Handler) openAPISpec {
+	t.Helper()
+
+	status, body := get(t, router, openAPIPath)
+	require.Equal(t, http.StatusOK, status, "the spec is served without a token")
+
+	var spec openAPISpec
+	require.NoError(t, json.Unmarshal(body, &spec))
+	require.True(t, strings.HasPrefix(spec.OpenAPI, "3."))
+
+	return spec
+}
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	router := routes()
+	spec := loadSpec(t, router)
+
+	routed := map[string]bool{}
+
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		// Subrouters and prefixes without a method only group other routes.
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range methods {
+			routed[method+" "+template] = true
+
+			require.Contains(t, spec.Paths[template], strings.ToLower(method),
+				"route %s %s is missing from the OpenAPI spec", method, template)
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+
+			require.True(t, routed[strings.ToUpper(method)+" "+path],
+				"the OpenAPI spec documents %s %s, which is not routed", method, path)
+		}
+	}
+}
+
+// jsonFields returns the JSON names of the fields of a struct.
+func jsonFields(value any) []string {
+	var fields []string
+
+	valueType := reflect.TypeOf(value)
+
+	for i := range valueType.NumField() {
+		name, _, _ := strings.Cut(valueType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+
+	slices.Sort(fields)
+
+	return fields
+}
+
+func TestOpenAPISchemasMatchDomain(t *testing.T) {
+	spec := loadSpec(t, routes())
+
+	tests := []struct {
+		schema string
+		value  any
+	}{
+		{schema: "Wallet", value: domain.Wallet{}},
+		{schema: "WalletInfo", value: domain.WalletInfo{}},
+		{schema: "WalletPatch", value: domain.WalletPatch{}},
+		{schema: "WalletPage", value: domain.WalletPage{}},
+		{schema: "BalanceChange", value: domain.BalanceChange{}},
+		{schema: "Transfer", value: domain.Transfer{}},
+		{schema: "TransferResult", value: domain.TransferResult{}},
+		{schema: "WalletTransaction", value: domain.WalletTransaction{}},
+		{schema: "TransactionPage", value: domain.TransactionPage{}},
+		{schema: "FieldError", value: domain.FieldError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			schema, ok := spec.Components.Schemas[tt.schema]
+			require.True(t, ok, "schema %s is missing", tt.schema)
+
+			properties := make([]string, 0, len(schema.Properties))
+			for property := range schema.Properties {
+				properties = append(properties, property)
+			}
+
+			slices.Sort(properties)
+
+			require.Equal(t, jsonFields(tt.value), properties)
+		})
+	}
+}
+
+func TestOpenAPIWalletPatchBody(t *testing.T) {
+	spec := loadSpec(t, routes())
+
+	var patch struct {
+		RequestBody struct {
+			Ref string `json:"$ref"`
+		} `json:"requestBody"`
+	}
+
+	require.NoError(t, json.Unmarshal(spec.Paths["/api/v1/wallets/{walletId}"]["patch"], &patch))
+	require.Equal(t, "#/components/requestBodies/WalletPatch", patch.RequestBody.Ref)
+
+	body, ok := spec.Components.RequestBodies["WalletPatch"]
+	require.True(t, ok)
+	require.Equal(t, "#/components/schemas/WalletPatch", body.Content["application/merge-patch+json"].Schema.Ref)
+}
+
+func TestSwaggerUI(t *testing.T) {
+	router := routes()
+
+	status, body := get(t, router, "/api/v1/docs/")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, string(body), "swagger-ui")
+
+	status, body = get(t, router, "/api/v1/docs/swagger-initializer.js")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, string(body), openAPIPath, "Swagger UI loads our spec, not the bundled example")
+}
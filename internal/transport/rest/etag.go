@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"wallet-service/internal/domain"
+)
+
+const (
+	etagHeader    = "ETag"
+	ifMatchHeader = "If-Match"
+)
+
+var (
+	ErrIfMatchRequired = errors.New("If-Match header is required, use the ETag of the wallet")
+	ErrIfMatchInvalid  = errors.New("If-Match header must be a single strong entity tag or *")
+)
+
+// setETag exposes the wallet version as a strong entity tag, so a client can
+// make its next change conditional on the wallet being unchanged.
+func setETag(w http.ResponseWriter, wallet domain.Wallet) {
+	w.Header().Set(etagHeader, strconv.Quote(strconv.FormatInt(wallet.Version, 10)))
+}
+
+// getIfMatch returns the wallet version required by the If-Match header, or
+// domain.AnyVersion for If-Match: *.
+func getIfMatch(r *http.Request) (int64, error) {
+	value := strings.TrimSpace(r.Header.Get(ifMatchHeader))
+
+	switch {
+	case value == "":
+		return 0, ErrIfMatchRequired
+	case value == "*":
+		return domain.AnyVersion, nil
+	}
+
+	// Weak tags are rejected as If-Match uses the strong comparison.
+	unquoted, err := strconv.Unquote(value)
+	if err != nil || !strings.HasPrefix(value, `"`) {
+		return 0, ErrIfMatchInvalid
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version <= domain.AnyVersion {
+		return 0, fmt.Errorf("%w: %s", ErrIfMatchInvalid, value)
+	}
+
+	return version, nil
+}
+
+// writeIfMatchError answers a request whose If-Match header is missing with 428
+// and one whose header is malformed with 400.
+func writeIfMatchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrIfMatchRequired) {
+		writeError(w, http.StatusPreconditionRequired, codePreconditionRequired, err.Error())
+
+		return
+	}
+
+	writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+}
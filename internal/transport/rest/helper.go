@@ -1,17 +1,22 @@
 package rest
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"wallet-service/internal/domain"
 )
 
-// func getUserId(r *http.Request) uuid.UUID {
-// 	userId := r.Context().Value("userId").(string)
-// 	return uuid.MustParse(userId)
-// }
+func getUserId(r *http.Request) uuid.UUID {
+	userId, _ := r.Context().Value(userIdContextKey).(uuid.UUID)
+
+	return userId
+}
 
 func getWalletId(r *http.Request) (uuid.UUID, error) {
 	walletId := mux.Vars(r)["walletId"]
@@ -23,3 +28,33 @@ func getWalletId(r *http.Request) (uuid.UUID, error) {
 
 	return walletIdParsed, nil
 }
+
+// getLimit reads the ?limit= page size. An absent limit is 0 and left for the
+// service to default; one that is not a positive integer is rejected.
+func getLimit(query url.Values) (int, error) {
+	limit := query.Get("limit")
+	if limit == "" {
+		return 0, nil
+	}
+
+	limitParsed, err := strconv.Atoi(limit)
+	if err != nil || limitParsed <= 0 {
+		return 0, fmt.Errorf("%w: %q", domain.ErrInvalidLimit, limit)
+	}
+
+	return limitParsed, nil
+}
+
+// writeQueryError answers a request with invalid query parameters. A domain
+// error keeps its code, anything else is a plain bad request.
+func writeQueryError(w http.ResponseWriter, err error) {
+	var domainErr *domain.Error
+
+	if errors.As(err, &domainErr) {
+		errorResponse(w, err)
+
+		return
+	}
+
+	writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+}
@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"wallet-service/internal/domain"
+)
+
+func TestGetLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    int
+		wantErr bool
+	}{
+		{name: "absent", query: "", want: 0},
+		{name: "empty", query: "limit=", want: 0},
+		{name: "positive", query: "limit=20", want: 20},
+		{name: "above the maximum is left for the service to cap", query: "limit=1000", want: 1000},
+		{name: "zero", query: "limit=0", wantErr: true},
+		{name: "negative", query: "limit=-5", wantErr: true},
+		{name: "not a number", query: "limit=ten", wantErr: true},
+		{name: "fraction", query: "limit=2.5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			limit, err := getLimit(query)
+			if tt.wantErr {
+				require.ErrorIs(t, err, domain.ErrInvalidLimit)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, limit)
+		})
+	}
+}
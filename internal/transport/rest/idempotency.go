@@ -0,0 +1,153 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"wallet-service/internal/domain"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyCleanupTimeout = 10 * time.Second
+)
+
+type idempotencyStore interface {
+	Reserve(ctx context.Context, userId uuid.UUID, key, requestHash string, ttl time.Duration) (domain.IdempotentResponse, bool, error)
+	Complete(ctx context.Context, userId uuid.UUID, key string, resp domain.IdempotentResponse) error
+	Release(ctx context.Context, userId uuid.UUID, key string) error
+}
+
+// idempotency executes a mutating request carrying an Idempotency-Key header at
+// most once per user and key, replaying the stored response for every retry.
+func (s *Server) idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+
+		if key == "" || !isMutating(r.Method) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			writeError(w, http.StatusBadRequest, codeBadRequest, "Idempotency-Key is too long")
+
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeBodyError(w, err)
+
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		userId := getUserId(r)
+
+		stored, reserved, err := s.idempotencyStore.Reserve(r.Context(), userId, key, requestHash(r, body), s.idempotencyTTL)
+		if err != nil {
+			errorResponse(w, err)
+
+			return
+		}
+
+		if !reserved {
+			replay(w, stored)
+
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		// The outcome is stored even if the client has already gone away:
+		// that is exactly the client that is going to retry.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), idempotencyCleanupTimeout)
+		defer cancel()
+
+		if recorder.statusCode >= http.StatusInternalServerError {
+			if err := s.idempotencyStore.Release(ctx, userId, key); err != nil {
+				logrus.Errorf("Idempotency release error: %v\n", err)
+			}
+
+			return
+		}
+
+		if err := s.idempotencyStore.Complete(ctx, userId, key, domain.IdempotentResponse{
+			StatusCode: recorder.statusCode,
+			Headers:    recorder.Header().Clone(),
+			Body:       recorder.body.Bytes(),
+		}); err != nil {
+			logrus.Errorf("Idempotency complete error: %v\n", err)
+		}
+	})
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// requestHash fingerprints the request so that a key reused for a different
+// request is rejected instead of replaying an unrelated response. The If-Match
+// precondition is part of the request: a retry against another wallet version
+// is a different request.
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write([]byte(ifMatchHeader + ": " + strings.TrimSpace(r.Header.Get(ifMatchHeader)) + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, stored domain.IdempotentResponse) {
+	for name, values := range stored.Headers {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.WriteHeader(stored.StatusCode)
+
+	if _, err := w.Write(stored.Body); err != nil {
+		logrus.Errorf("Write HTTP error: %v\n", err)
+	}
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+
+	return r.ResponseWriter.Write(data) //nolint:wrapcheck
+}
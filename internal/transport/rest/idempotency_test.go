@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestHash(t *testing.T) {
+	request := func(method, path, ifMatch string) *http.Request {
+		r := httptest.NewRequest(method, path, nil)
+		if ifMatch != "" {
+			r.Header.Set(ifMatchHeader, ifMatch)
+		}
+
+		return r
+	}
+
+	body := []byte(`{"name":"savings"}`)
+	base := requestHash(request(http.MethodPatch, "/api/v1/wallets/1", `"3"`), body)
+
+	tests := []struct {
+		name string
+		r    *http.Request
+		body []byte
+		same bool
+	}{
+		{name: "identical request", r: request(http.MethodPatch, "/api/v1/wallets/1", `"3"`), body: body, same: true},
+		{name: "surrounding whitespace", r: request(http.MethodPatch, "/api/v1/wallets/1", ` "3" `), body: body, same: true},
+		{name: "other method", r: request(http.MethodPut, "/api/v1/wallets/1", `"3"`), body: body},
+		{name: "other path", r: request(http.MethodPatch, "/api/v1/wallets/2", `"3"`), body: body},
+		{name: "other body", r: request(http.MethodPatch, "/api/v1/wallets/1", `"3"`), body: []byte(`{"name":"other"}`)},
+		{name: "other If-Match", r: request(http.MethodPatch, "/api/v1/wallets/1", `"4"`), body: body},
+		{name: "any version", r: request(http.MethodPatch, "/api/v1/wallets/1", "*"), body: body},
+		{name: "no If-Match", r: request(http.MethodPatch, "/api/v1/wallets/1", ""), body: body},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.same {
+				require.Equal(t, base, requestHash(tt.r, tt.body))
+			} else {
+				require.NotEqual(t, base, requestHash(tt.r, tt.body))
+			}
+		})
+	}
+}
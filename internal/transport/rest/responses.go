@@ -6,12 +6,34 @@ import (
 	"net/http"
 
 	"github.com/sirupsen/logrus"
+	"wallet-service/internal/domain"
 )
 
 var ErrHTTPMethod = errors.New("incorrect HTTP method")
 
+const (
+	codeBadRequest           = "bad_request"
+	codeUnauthorized         = "unauthorized"
+	codeMethodNotAllowed     = "method_not_allowed"
+	codeRouteNotFound        = "route_not_found"
+	codePreconditionRequired = "precondition_required"
+	codeUnsupportedMediaType = "unsupported_media_type"
+	codePayloadTooLarge      = "payload_too_large"
+	codeInternal             = "internal_error"
+)
+
 type Map map[string]interface{}
 
+type errorBody struct {
+	Error errorDetails `json:"error"`
+}
+
+type errorDetails struct {
+	Code    string              `json:"code"`
+	Message string              `json:"message"`
+	Fields  []domain.FieldError `json:"fields,omitempty"`
+}
+
 func response(w http.ResponseWriter, statusCode int, message any) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(statusCode)
@@ -29,3 +51,53 @@ func response(w http.ResponseWriter, statusCode int, message any) {
 		logrus.Panicf("Write HTTP error: %v\n", err)
 	}
 }
+
+func writeError(w http.ResponseWriter, statusCode int, code, message string) {
+	response(w, statusCode, errorBody{
+		Error: errorDetails{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
+
+// errorResponse is the single place where errors returned by the service
+// become HTTP responses. Anything that is not a domain.Error is an internal
+// failure whose details are logged rather than sent to the client.
+func errorResponse(w http.ResponseWriter, err error) {
+	var domainErr *domain.Error
+
+	if !errors.As(err, &domainErr) {
+		logrus.Errorf("Internal error: %v\n", err)
+		writeError(w, http.StatusInternalServerError, codeInternal, http.StatusText(http.StatusInternalServerError))
+
+		return
+	}
+
+	response(w, errorStatus(domainErr.Kind), errorBody{
+		Error: errorDetails{
+			Code:    domainErr.Code,
+			Message: err.Error(),
+			Fields:  domainErr.Fields,
+		},
+	})
+}
+
+func errorStatus(kind domain.ErrorKind) int {
+	switch kind {
+	case domain.KindNotFound:
+		return http.StatusNotFound
+	case domain.KindForbidden:
+		return http.StatusForbidden
+	case domain.KindConflict:
+		return http.StatusConflict
+	case domain.KindValidation:
+		return http.StatusBadRequest
+	case domain.KindInsufficientFunds, domain.KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case domain.KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
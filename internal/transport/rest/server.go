@@ -7,7 +7,6 @@ import (
 	"time"
 
 	configs "wallet-service/internal/config"
-	"wallet-service/internal/repository"
 	"wallet-service/internal/service"
 
 	"github.com/gorilla/mux"
@@ -16,15 +15,21 @@ import (
 const maxHeaderBytes = 1 << 20
 
 type Server struct {
-	server   *http.Server
-	services *service.Service
-	userRepo *repository.UsersRepository
+	server           *http.Server
+	services         *service.Service
+	authenticator    authenticator
+	idempotencyStore idempotencyStore
+	idempotencyTTL   time.Duration
 }
 
-func New(services *service.Service, userRepo *repository.UsersRepository) *Server {
+func New(services *service.Service, authenticator authenticator, idempotencyStore idempotencyStore,
+	idempotencyTTL time.Duration,
+) *Server {
 	return &Server{
-		services: services,
-		userRepo: userRepo,
+		services:         services,
+		authenticator:    authenticator,
+		idempotencyStore: idempotencyStore,
+		idempotencyTTL:   idempotencyTTL,
 	}
 }
 
@@ -61,14 +66,29 @@ func (s *Server) Shutdown(ctx context.Context) error {
 
 func (s *Server) InitRoutes() *mux.Router {
 	r := mux.NewRouter()
+	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeError(w, http.StatusNotFound, codeRouteNotFound, "route not found")
+	})
+	r.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, ErrHTTPMethod.Error())
+	})
+
+	// The documentation is public, so it is routed before the authenticated API.
+	r.HandleFunc(openAPIPath, s.getOpenAPI).Methods(http.MethodGet)
+	r.PathPrefix(docsPath).Handler(docs()).Methods(http.MethodGet)
 
 	api := r.PathPrefix("/api/v1").Subrouter()
+	api.Use(limitBody, s.authenticate, s.idempotency)
 
 	api.HandleFunc("/wallets", s.getWallets).Methods(http.MethodGet)
 	api.HandleFunc("/wallets/{walletId}", s.getWallet).Methods(http.MethodGet)
 	api.HandleFunc("/wallets", s.createWallet).Methods(http.MethodPost)
 	api.HandleFunc("/wallets/{walletId}", s.updateWallet).Methods(http.MethodPatch)
 	api.HandleFunc("/wallets/{walletId}", s.deleteWallet).Methods(http.MethodDelete)
+	api.HandleFunc("/wallets/{walletId}/deposit", s.deposit).Methods(http.MethodPost)
+	api.HandleFunc("/wallets/{walletId}/withdraw", s.withdraw).Methods(http.MethodPost)
+	api.HandleFunc("/wallets/{walletId}/transactions", s.getTransactions).Methods(http.MethodGet)
+	api.HandleFunc("/transfers", s.createTransfer).Methods(http.MethodPost)
 
 	return r
 }
@@ -0,0 +1,82 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"wallet-service/internal/domain"
+)
+
+func (h *Server) getTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, ErrHTTPMethod.Error())
+
+		return
+	}
+
+	walletId, err := getWalletId(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+
+		return
+	}
+
+	filter, err := getTransactionFilter(r)
+	if err != nil {
+		writeQueryError(w, err)
+
+		return
+	}
+
+	userId := getUserId(r).String()
+
+	page, err := h.services.GetTransactions(r.Context(), walletId, userId, filter)
+	if err != nil {
+		errorResponse(w, err)
+
+		return
+	}
+
+	response(w, http.StatusOK, page)
+}
+
+// getTransactionFilter reads ?limit=&cursor=&from=&to=&type= query parameters.
+// Dates are RFC 3339; type may be repeated or comma-separated.
+func getTransactionFilter(r *http.Request) (domain.TransactionFilter, error) {
+	query := r.URL.Query()
+
+	filter := domain.TransactionFilter{
+		Cursor: query.Get("cursor"),
+	}
+
+	limit, err := getLimit(query)
+	if err != nil {
+		return domain.TransactionFilter{}, err
+	}
+
+	filter.Limit = limit
+
+	for name, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return domain.TransactionFilter{}, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		*dst = &parsed
+	}
+
+	for _, types := range query["type"] {
+		for txType := range strings.SplitSeq(types, ",") {
+			filter.Types = append(filter.Types, domain.WalletTransactionType(strings.TrimSpace(txType)))
+		}
+	}
+
+	return filter, nil
+}
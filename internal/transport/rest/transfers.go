@@ -0,0 +1,36 @@
+package rest
+
+import (
+	"net/http"
+
+	"wallet-service/internal/domain"
+)
+
+func (h *Server) createTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, ErrHTTPMethod.Error())
+
+		return
+	}
+
+	userId := getUserId(r).String()
+
+	var transfer domain.Transfer
+
+	if err := decodeJSON(r, &transfer); err != nil {
+		writeBodyError(w, err)
+
+		return
+	}
+
+	result, err := h.services.Transfer(r.Context(), transfer, userId)
+	if err != nil {
+		errorResponse(w, err)
+
+		return
+	}
+
+	response(w, http.StatusOK, Map{
+		"transfer": result,
+	})
+}
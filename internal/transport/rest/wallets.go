@@ -1,7 +1,10 @@
 package rest
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"time"
 
@@ -9,50 +12,50 @@ import (
 	"wallet-service/internal/domain"
 )
 
-const userId = "a737d022-eabd-4b04-ac0b-87ee9cb10885"
-
 func (h *Server) createWallet(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		response(w, http.StatusMethodNotAllowed, ErrHTTPMethod)
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, ErrHTTPMethod.Error())
 
 		return
 	}
 
 	var walletInfo domain.WalletInfo
 
-	userIdConv := uuid.MustParse(userId)
+	userId := getUserId(r).String()
 
-	user, err := h.userRepo.GetUser(r.Context(), userIdConv)
-	if err != nil {
-		response(w, http.StatusInternalServerError, err.Error())
+	if err := decodeJSON(r, &walletInfo); err != nil {
+		writeBodyError(w, err)
 
 		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&walletInfo); err != nil {
-		response(w, http.StatusBadRequest, err.Error())
-
-		return
+	// The opening balance is optional. Money.UnmarshalJSON rejects a balance
+	// without a currency, so a zero Money means it was omitted and the wallet
+	// opens empty in its own currency.
+	balance := walletInfo.Balance
+	if balance == (domain.Money{}) {
+		balance = domain.NewMoney(0, walletInfo.Currency)
 	}
 
 	wallet := domain.Wallet{
 		Id:        uuid.New(),
-		UserId:    user.Id.String(),
+		UserId:    userId,
 		Name:      walletInfo.Name,
-		Balance:   walletInfo.Balance,
+		Balance:   balance,
 		Currency:  walletInfo.Currency,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		DeletedAt: nil,
 	}
 
-	newWallet, err := h.services.CreateWallet(r.Context(), wallet, user.Id.String())
+	newWallet, err := h.services.CreateWallet(r.Context(), wallet, userId)
 	if err != nil {
-		response(w, http.StatusInternalServerError, err.Error())
+		errorResponse(w, err)
 
 		return
 	}
 
+	setETag(w, newWallet)
 	response(w, http.StatusCreated, Map{
 		"wallet": newWallet,
 	})
@@ -60,34 +63,28 @@ func (h *Server) createWallet(w http.ResponseWriter, r *http.Request) {
 
 func (h *Server) getWallet(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		response(w, http.StatusMethodNotAllowed, ErrHTTPMethod)
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, ErrHTTPMethod.Error())
 
 		return
 	}
 
 	walletId, err := getWalletId(r)
 	if err != nil {
-		response(w, http.StatusBadRequest, err.Error())
+		writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
 
 		return
 	}
 
-	userIdConv := uuid.MustParse(userId)
-
-	user, err := h.userRepo.GetUser(r.Context(), userIdConv)
-	if err != nil {
-		response(w, http.StatusInternalServerError, err.Error())
-
-		return
-	}
+	userId := getUserId(r).String()
 
-	wlt, err := h.services.GetWallet(r.Context(), walletId, user.Id.String())
+	wlt, err := h.services.GetWallet(r.Context(), walletId, userId)
 	if err != nil {
-		response(w, http.StatusInternalServerError, err.Error())
+		errorResponse(w, err)
 
 		return
 	}
 
+	setETag(w, wlt)
 	response(w, http.StatusOK, Map{
 		"wallet": wlt,
 	})
@@ -95,71 +92,133 @@ func (h *Server) getWallet(w http.ResponseWriter, r *http.Request) {
 
 func (h *Server) getWallets(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		response(w, http.StatusMethodNotAllowed, ErrHTTPMethod)
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, ErrHTTPMethod.Error())
 
 		return
 	}
 
-	userIdConv := uuid.MustParse(userId)
-
-	user, err := h.userRepo.GetUser(r.Context(), userIdConv)
+	filter, err := getWalletFilter(r)
 	if err != nil {
-		response(w, http.StatusInternalServerError, err.Error())
+		writeQueryError(w, err)
 
 		return
 	}
 
-	wallets, err := h.services.GetWallets(r.Context(), user.Id.String())
+	userId := getUserId(r).String()
+
+	page, err := h.services.GetWallets(r.Context(), userId, filter)
 	if err != nil {
-		response(w, http.StatusInternalServerError, err.Error())
+		errorResponse(w, err)
 
 		return
 	}
 
-	response(w, http.StatusOK, Map{
-		"wallets": wallets,
-	})
+	response(w, http.StatusOK, page)
 }
 
+// getWalletFilter reads ?limit=&cursor=&currency=&namePrefix=&minBalance=
+// &maxBalance=&sort=&order= query parameters. Balance bounds are decimal
+// amounts in the currency given by ?currency=, which they therefore require.
+func getWalletFilter(r *http.Request) (domain.WalletFilter, error) {
+	query := r.URL.Query()
+
+	filter := domain.WalletFilter{
+		Cursor:     query.Get("cursor"),
+		Currency:   query.Get("currency"),
+		NamePrefix: query.Get("namePrefix"),
+		Sort:       domain.WalletSort(query.Get("sort")),
+		Order:      domain.SortOrder(query.Get("order")),
+	}
+
+	limit, err := getLimit(query)
+	if err != nil {
+		return domain.WalletFilter{}, err
+	}
+
+	filter.Limit = limit
+
+	for name, dst := range map[string]**domain.Money{"minBalance": &filter.MinBalance, "maxBalance": &filter.MaxBalance} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+
+		if filter.Currency == "" {
+			return domain.WalletFilter{}, fmt.Errorf("%s requires currency", name)
+		}
+
+		parsed, err := domain.ParseMoney(value, filter.Currency)
+		if err != nil {
+			return domain.WalletFilter{}, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		*dst = &parsed
+	}
+
+	return filter, nil
+}
+
+// mergePatchContentType is the media type of a JSON merge patch (RFC 7396).
+const mergePatchContentType = "application/merge-patch+json"
+
+// updateWallet applies a JSON merge patch to the mutable fields of the wallet.
 func (h *Server) updateWallet(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPatch {
-		response(w, http.StatusMethodNotAllowed, ErrHTTPMethod)
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, ErrHTTPMethod.Error())
 
 		return
 	}
 
 	walletId, err := getWalletId(r)
 	if err != nil {
-		response(w, http.StatusBadRequest, err.Error())
+		writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
 
 		return
 	}
 
-	userIdConv := uuid.MustParse(userId)
-
-	user, err := h.userRepo.GetUser(r.Context(), userIdConv)
+	version, err := getIfMatch(r)
 	if err != nil {
-		response(w, http.StatusInternalServerError, err.Error())
+		writeIfMatchError(w, err)
 
 		return
 	}
 
-	var updateWallet domain.WalletUpdate
+	// Plain JSON is still accepted from clients written before merge patches.
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+			writeError(w, http.StatusUnsupportedMediaType, codeUnsupportedMediaType,
+				"PATCH body must be "+mergePatchContentType)
+
+			return
+		}
+	}
+
+	userId := getUserId(r).String()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeBodyError(w, err)
+
+		return
+	}
 
-	if err := json.NewDecoder(r.Body).Decode(&updateWallet); err != nil {
-		response(w, http.StatusBadRequest, err.Error())
+	patch, err := domain.ParseWalletPatch(body)
+	if err != nil {
+		errorResponse(w, err)
 
 		return
 	}
 
 	updatedWallet, err := h.services.UpdateWallet(r.Context(), walletId,
-		user.Id.String(), updateWallet)
+		userId, patch, version)
 	if err != nil {
-		response(w, http.StatusInternalServerError, err.Error())
+		errorResponse(w, err)
 
 		return
 	}
 
+	setETag(w, updatedWallet)
 	response(w, http.StatusOK, Map{
 		"updated wallet": updatedWallet,
 	})
@@ -167,32 +226,79 @@ func (h *Server) updateWallet(w http.ResponseWriter, r *http.Request) {
 
 func (h *Server) deleteWallet(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
-		response(w, http.StatusMethodNotAllowed, ErrHTTPMethod)
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, ErrHTTPMethod.Error())
 
 		return
 	}
 
 	walletId, err := getWalletId(r)
 	if err != nil {
-		response(w, http.StatusBadRequest, err.Error())
+		writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
 
 		return
 	}
 
-	userIdConv := uuid.MustParse(userId)
-
-	user, err := h.userRepo.GetUser(r.Context(), userIdConv)
+	version, err := getIfMatch(r)
 	if err != nil {
-		response(w, http.StatusInternalServerError, err.Error())
+		writeIfMatchError(w, err)
 
 		return
 	}
 
-	if err := h.services.DeleteWallet(r.Context(), walletId, user.Id.String()); err != nil {
-		response(w, http.StatusInternalServerError, err.Error())
+	userId := getUserId(r).String()
+
+	if err := h.services.DeleteWallet(r.Context(), walletId, userId, version); err != nil {
+		errorResponse(w, err)
 
 		return
 	}
 
 	response(w, http.StatusNoContent, nil)
 }
+
+type balanceOperation func(ctx context.Context, walletId uuid.UUID, userId string, amount domain.Money) (domain.Wallet, error)
+
+func (h *Server) deposit(w http.ResponseWriter, r *http.Request) {
+	h.changeBalance(w, r, h.services.Deposit)
+}
+
+func (h *Server) withdraw(w http.ResponseWriter, r *http.Request) {
+	h.changeBalance(w, r, h.services.Withdraw)
+}
+
+func (h *Server) changeBalance(w http.ResponseWriter, r *http.Request, operation balanceOperation) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, ErrHTTPMethod.Error())
+
+		return
+	}
+
+	walletId, err := getWalletId(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+
+		return
+	}
+
+	userId := getUserId(r).String()
+
+	var balanceChange domain.BalanceChange
+
+	if err := decodeJSON(r, &balanceChange); err != nil {
+		writeBodyError(w, err)
+
+		return
+	}
+
+	wallet, err := operation(r.Context(), walletId, userId, balanceChange.Amount)
+	if err != nil {
+		errorResponse(w, err)
+
+		return
+	}
+
+	setETag(w, wallet)
+	response(w, http.StatusOK, Map{
+		"wallet": wallet,
+	})
+}
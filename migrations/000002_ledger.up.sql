@@ -0,0 +1,41 @@
+CREATE TABLE transactions (
+    id UUID PRIMARY KEY NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- wallet_id IS NULL marks the external account that money enters and leaves through.
+CREATE TABLE ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id UUID NOT NULL,
+    wallet_id UUID,
+    direction VARCHAR(6) NOT NULL,
+    amount NUMERIC NOT NULL,
+    currency VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+
+    CONSTRAINT fk_ledger_entries_transaction FOREIGN KEY(transaction_id) REFERENCES transactions(id),
+    CONSTRAINT fk_ledger_entries_wallet FOREIGN KEY(wallet_id) REFERENCES wallets(id),
+    CONSTRAINT chk_ledger_entries_direction CHECK (direction IN ('debit', 'credit')),
+    CONSTRAINT chk_ledger_entries_amount CHECK (amount > 0)
+);
+
+CREATE INDEX idx_ledger_entries_wallet_id ON ledger_entries(wallet_id, id);
+
+-- Existing balances become opening deposits, or withdrawals for the negative
+-- ones, so that the ledger sum of every wallet equals its stored balance.
+WITH opening AS (
+    SELECT gen_random_uuid() AS transaction_id, id AS wallet_id, abs(balance) AS amount, currency, created_at,
+        balance > 0 AS positive
+    FROM wallets
+    WHERE balance <> 0
+), opening_transactions AS (
+    INSERT INTO transactions (id, type, created_at)
+    SELECT transaction_id, CASE WHEN positive THEN 'deposit' ELSE 'withdrawal' END, created_at FROM opening
+)
+INSERT INTO ledger_entries (transaction_id, wallet_id, direction, amount, currency, created_at)
+SELECT transaction_id, NULL, CASE WHEN positive THEN 'debit' ELSE 'credit' END, amount, currency, created_at
+FROM opening
+UNION ALL
+SELECT transaction_id, wallet_id, CASE WHEN positive THEN 'credit' ELSE 'debit' END, amount, currency, created_at
+FROM opening;
@@ -0,0 +1,17 @@
+-- Digits after the decimal separator of the ISO 4217 minor unit; mirrors domain.CurrencyExponent.
+CREATE FUNCTION pg_temp.currency_exponent(code TEXT) RETURNS INTEGER AS $$
+    SELECT CASE
+        WHEN code IN ('BIF', 'CLP', 'DJF', 'GNF', 'ISK', 'JPY', 'KMF', 'KRW', 'PYG', 'RWF', 'UGX', 'VND', 'VUV', 'XAF', 'XOF', 'XPF') THEN 0
+        WHEN code IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN 3
+        WHEN code IN ('CLF', 'UYW') THEN 4
+        ELSE 2
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+ALTER TABLE wallets
+    ALTER COLUMN balance TYPE BIGINT
+    USING ROUND(balance::NUMERIC * POWER(10::NUMERIC, pg_temp.currency_exponent(currency)))::BIGINT;
+
+ALTER TABLE ledger_entries
+    ALTER COLUMN amount TYPE BIGINT
+    USING ROUND(amount * POWER(10::NUMERIC, pg_temp.currency_exponent(currency)))::BIGINT;
@@ -0,0 +1,3 @@
+ALTER TABLE wallets
+    DROP COLUMN closure_requested_at,
+    DROP COLUMN status;
@@ -0,0 +1,3 @@
+ALTER TABLE wallets
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active',
+    ADD COLUMN closure_requested_at TIMESTAMP WITH TIME ZONE;
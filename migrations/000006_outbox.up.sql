@@ -0,0 +1,10 @@
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_id UUID NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_outbox_pending ON outbox(id) WHERE sent_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN last_event_at TIMESTAMP WITH TIME ZONE;
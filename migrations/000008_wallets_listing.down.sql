@@ -0,0 +1,3 @@
+DROP INDEX idx_wallets_user_balance;
+DROP INDEX idx_wallets_user_name;
+DROP INDEX idx_wallets_user_created_at;
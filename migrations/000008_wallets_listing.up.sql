@@ -0,0 +1,4 @@
+-- One index per sort key of the wallet listing, each with id as the tie-breaker of the keyset.
+CREATE INDEX idx_wallets_user_created_at ON wallets(user_id, created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_wallets_user_name ON wallets(user_id, name, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_wallets_user_balance ON wallets(user_id, balance, id) WHERE deleted_at IS NULL;
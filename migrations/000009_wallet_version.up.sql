@@ -0,0 +1 @@
+ALTER TABLE wallets ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
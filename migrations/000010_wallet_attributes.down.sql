@@ -0,0 +1,6 @@
+DROP INDEX idx_wallets_user_default;
+
+ALTER TABLE wallets
+    DROP COLUMN is_default,
+    DROP COLUMN tags,
+    DROP COLUMN description;
@@ -0,0 +1,7 @@
+ALTER TABLE wallets
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN is_default BOOLEAN NOT NULL DEFAULT false;
+
+-- A user has at most one default wallet.
+CREATE UNIQUE INDEX idx_wallets_user_default ON wallets(user_id) WHERE is_default AND deleted_at IS NULL;
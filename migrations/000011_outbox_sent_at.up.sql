@@ -0,0 +1,2 @@
+-- Sent messages are purged by age.
+CREATE INDEX idx_outbox_sent_at ON outbox(sent_at) WHERE sent_at IS NOT NULL;
@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func (s *IntegrationTestSuite) TestAuthentication() {
+	url := fmt.Sprintf("http://localhost:%s%s", s.cfg.HTTP.Port, walletPath)
+
+	s.Run("missing token", func() {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		s.Require().NoError(err)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+		s.Require().NoError(resp.Body.Close())
+
+		s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	s.Run("token with a bad signature", func() {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		s.Require().NoError(err)
+
+		req.Header.Set("Authorization", "Bearer "+s.accessToken(existingUser)+"x")
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+		s.Require().NoError(resp.Body.Close())
+
+		s.Require().Equal(http.StatusUnauthorized, resp.StatusCode)
+	})
+}
@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"wallet-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type errorResponse struct {
+	Error struct {
+		Code    string              `json:"code"`
+		Message string              `json:"message"`
+		Fields  []domain.FieldError `json:"fields"`
+	} `json:"error"`
+}
+
+func (s *IntegrationTestSuite) TestErrorBody() {
+	_, err := s.usersRepo.UpsertUser(context.Background(), existingUser, time.Now())
+	s.Require().NoError(err)
+
+	s.Run("wallet not found", func() {
+		var body errorResponse
+
+		s.sendHTTPRequest(http.MethodGet, walletPath+"/"+uuid.NewString(), http.StatusNotFound, nil, &body, existingUser)
+
+		s.Require().Equal("wallet_not_found", body.Error.Code)
+	})
+
+	s.Run("invalid wallet id", func() {
+		var body errorResponse
+
+		s.sendHTTPRequest(http.MethodGet, walletPath+"/not-a-uuid", http.StatusBadRequest, nil, &body, existingUser)
+
+		s.Require().Equal("bad_request", body.Error.Code)
+	})
+}
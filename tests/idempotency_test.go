@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"wallet-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestIdempotentCreateWallet() {
+	_, err := s.usersRepo.UpsertUser(context.Background(), existingUser, time.Now())
+	s.Require().NoError(err)
+
+	info := domain.WalletInfo{Name: "idempotent", Currency: "USD"}
+	headers := http.Header{"Idempotency-Key": {uuid.NewString()}}
+
+	var first, second struct {
+		Wallet domain.Wallet `json:"wallet"`
+	}
+
+	s.Run("retry replays the first response", func() {
+		s.sendHTTPRequestWithHeaders(http.MethodPost, walletPath, http.StatusCreated, &info, &first, existingUser, headers)
+
+		replayHeaders := s.sendHTTPRequestWithHeaders(http.MethodPost, walletPath, http.StatusCreated, &info, &second,
+			existingUser, headers)
+
+		s.Require().Equal(first.Wallet.Id, second.Wallet.Id)
+		s.Require().Equal("true", replayHeaders.Get("Idempotent-Replayed"))
+	})
+
+	s.Run("key reused for a different request", func() {
+		other := domain.WalletInfo{Name: "other", Currency: "USD"}
+
+		s.sendHTTPRequestWithHeaders(http.MethodPost, walletPath, http.StatusUnprocessableEntity, &other, nil, existingUser,
+			headers)
+	})
+
+	s.Run("key reused with a different precondition", func() {
+		name := "renamed"
+		update := domain.WalletPatch{Name: &name}
+		path := walletPath + "/" + first.Wallet.Id.String()
+		key := uuid.NewString()
+
+		s.sendHTTPRequestWithHeaders(http.MethodPatch, path, http.StatusOK, &update, nil, existingUser,
+			http.Header{"Idempotency-Key": {key}, "If-Match": {"*"}})
+
+		s.sendHTTPRequestWithHeaders(http.MethodPatch, path, http.StatusUnprocessableEntity, &update, nil, existingUser,
+			http.Header{"Idempotency-Key": {key}, "If-Match": {`"1"`}})
+	})
+}
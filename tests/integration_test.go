@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"wallet-service/internal/auth"
 	configs "wallet-service/internal/config"
 	"wallet-service/internal/domain"
 	"wallet-service/internal/repository"
@@ -18,9 +19,12 @@ import (
 	"wallet-service/internal/transport/kafka/producer"
 	"wallet-service/internal/transport/rest"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/suite"
 )
 
+const testHMACSecret = "integration-test-secret"
+
 type IntegrationTestSuite struct {
 	suite.Suite
 
@@ -50,11 +54,24 @@ func (s *IntegrationTestSuite) SetupSuite() {
 	err = s.psql.Up()
 	s.Require().NoError(err)
 
-	s.kProducer = producer.New(s.cfg)
+	s.kProducer, err = producer.New(s.cfg)
+	s.Require().NoError(err)
+
+	s.usersRepo = repository.NewUsersRepository(s.psql.Database())
+	s.walletsRepo = repository.NewWalletRepository(s.psql.Database())
 
 	s.services = service.New(s.usersRepo, s.walletsRepo)
 
-	s.server = rest.New(s.services, s.usersRepo)
+	if s.cfg.Auth.HMACSecret == "" {
+		s.cfg.Auth.HMACSecret = testHMACSecret
+	}
+
+	authenticator, err := auth.New(s.cfg.Auth)
+	s.Require().NoError(err)
+
+	s.server = rest.New(s.services, authenticator,
+		repository.NewIdempotencyRepository(s.psql.Database(), s.cfg.HTTP.IdempotencyInFlightTimeout),
+		s.cfg.HTTP.IdempotencyTTL)
 
 	//nolint:testifylint
 	go func() {
@@ -70,6 +87,12 @@ func TestIntegrationSetupSuite(t *testing.T) {
 }
 
 func (s *IntegrationTestSuite) sendHTTPRequest(method, path string, statusCode int, entity, result any, user domain.User) {
+	s.sendHTTPRequestWithHeaders(method, path, statusCode, entity, result, user, nil)
+}
+
+func (s *IntegrationTestSuite) sendHTTPRequestWithHeaders(method, path string, statusCode int, entity, result any,
+	user domain.User, headers http.Header,
+) http.Header {
 	clientHTTP := http.Client{}
 	
 	entityJSON, err := json.Marshal(entity)
@@ -80,6 +103,14 @@ func (s *IntegrationTestSuite) sendHTTPRequest(method, path string, statusCode i
 	req, err := http.NewRequest(method, url, bytes.NewReader(entityJSON))
 	s.Require().NoError(err, "failed to create new request")
 
+	req.Header.Set("Authorization", "Bearer "+s.accessToken(user))
+
+	for name, values := range headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
 	resp, err := clientHTTP.Do(req)
 	s.Require().NoError(err)
 
@@ -98,9 +129,28 @@ func (s *IntegrationTestSuite) sendHTTPRequest(method, path string, statusCode i
 	}
 
 	if result == nil {
-		return
+		return resp.Header
 	}
 
 	err = json.NewDecoder(resp.Body).Decode(result)
 	s.Require().NoError(err)
+
+	return resp.Header
+}
+
+func (s *IntegrationTestSuite) accessToken(user domain.User) string {
+	claims := jwt.RegisteredClaims{
+		Subject:   user.Id.String(),
+		Issuer:    s.cfg.Auth.Issuer,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+
+	if s.cfg.Auth.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{s.cfg.Auth.Audience}
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.cfg.Auth.HMACSecret))
+	s.Require().NoError(err)
+
+	return signed
 }
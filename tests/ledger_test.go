@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"wallet-service/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func (s *IntegrationTestSuite) TestLedgerInvariants() {
+	user := domain.User{
+		Id: uuid.New(),
+	}
+
+	_, err := s.usersRepo.UpsertUser(context.Background(), user, time.Now())
+	s.Require().NoError(err)
+
+	createWallet := func(info domain.WalletInfo) domain.Wallet {
+		var created struct {
+			Wallet domain.Wallet `json:"wallet"`
+		}
+
+		s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &info, &created, user)
+
+		return created.Wallet
+	}
+
+	first := createWallet(domain.WalletInfo{Name: "first", Balance: domain.NewMoney(10000, "USD"), Currency: "USD"})
+	second := createWallet(domain.WalletInfo{Name: "second", Currency: "USD"})
+	walletIds := []uuid.UUID{first.Id, second.Id}
+
+	deposit := domain.BalanceChange{Amount: domain.NewMoney(2500, "USD")}
+	s.sendHTTPRequest(http.MethodPost, walletPath+"/"+first.Id.String()+"/deposit", http.StatusOK, &deposit, nil, user)
+
+	withdrawal := domain.BalanceChange{Amount: domain.NewMoney(1500, "USD")}
+	s.sendHTTPRequest(http.MethodPost, walletPath+"/"+first.Id.String()+"/withdraw", http.StatusOK, &withdrawal, nil, user)
+
+	transfer := domain.Transfer{FromWalletId: first.Id, ToWalletId: second.Id, Amount: domain.NewMoney(4000, "USD")}
+	s.sendHTTPRequest(http.MethodPost, transferPath, http.StatusOK, &transfer, nil, user)
+
+	// A failed withdrawal must leave neither entries nor a balance change behind.
+	overdraft := domain.BalanceChange{Amount: domain.NewMoney(100000, "USD")}
+	s.sendHTTPRequest(http.MethodPost, walletPath+"/"+second.Id.String()+"/withdraw", http.StatusUnprocessableEntity,
+		&overdraft, nil, user)
+
+	s.Run("balances equal the sum of ledger entries", func() {
+		for walletId, balance := range map[uuid.UUID]int64{first.Id: 7000, second.Id: 4000} {
+			stored, ledger := s.ledgerBalance(walletId)
+
+			s.Require().Equal(balance, stored)
+			s.Require().Equal(stored, ledger)
+		}
+	})
+
+	s.Run("every transaction balances", func() {
+		var unbalanced []uuid.UUID
+
+		err := s.psql.Database().SelectContext(context.Background(), &unbalanced,
+			`SELECT transaction_id
+			FROM ledger_entries
+			WHERE transaction_id IN (SELECT transaction_id FROM ledger_entries WHERE wallet_id = ANY($1))
+			GROUP BY transaction_id, currency
+			HAVING SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END) <> 0`,
+			pq.Array(walletIds))
+		s.Require().NoError(err)
+		s.Require().Empty(unbalanced)
+
+		var transactions int
+
+		err = s.psql.Database().GetContext(context.Background(), &transactions,
+			`SELECT COUNT(DISTINCT transaction_id) FROM ledger_entries WHERE wallet_id = ANY($1)`,
+			pq.Array(walletIds))
+		s.Require().NoError(err)
+		s.Require().Equal(4, transactions)
+	})
+
+	s.Run("posted transactions keep balances equal to the ledger", func() {
+		posted, err := s.walletsRepo.PostTransaction(context.Background(),
+			domain.NewTransferTransaction(second.Id, first.Id, domain.NewMoney(1000, "USD")))
+		s.Require().NoError(err)
+		s.Require().Len(posted.Entries, 2)
+
+		unbalanced := domain.NewDepositTransaction(first.Id, domain.NewMoney(500, "USD"))
+		unbalanced.Entries[0].Amount = domain.NewMoney(400, "USD")
+
+		_, err = s.walletsRepo.PostTransaction(context.Background(), unbalanced)
+		s.Require().ErrorIs(err, domain.ErrUnbalancedTransaction)
+
+		_, err = s.walletsRepo.PostTransaction(context.Background(),
+			domain.NewWithdrawalTransaction(second.Id, domain.NewMoney(100000, "USD")))
+		s.Require().ErrorIs(err, domain.ErrInsufficientFunds)
+
+		for walletId, balance := range map[uuid.UUID]int64{first.Id: 8000, second.Id: 3000} {
+			stored, ledger := s.ledgerBalance(walletId)
+
+			s.Require().Equal(balance, stored)
+			s.Require().Equal(stored, ledger)
+		}
+	})
+
+	s.Run("recompute rebuilds a drifted balance from the ledger", func() {
+		_, err := s.psql.Database().ExecContext(context.Background(),
+			`UPDATE wallets SET balance = balance + 100 WHERE id = $1`, second.Id)
+		s.Require().NoError(err)
+
+		wallet, err := s.walletsRepo.RecomputeBalance(context.Background(), second.Id)
+		s.Require().NoError(err)
+		s.Require().Equal(domain.NewMoney(3000, "USD"), wallet.Balance)
+
+		stored, ledger := s.ledgerBalance(second.Id)
+		s.Require().Equal(ledger, stored)
+	})
+}
+
+// ledgerBalance returns the stored balance of the wallet and the sum of its
+// ledger entries.
+func (s *IntegrationTestSuite) ledgerBalance(walletId uuid.UUID) (int64, int64) {
+	var stored, ledger int64
+
+	err := s.psql.Database().GetContext(context.Background(), &stored,
+		`SELECT balance FROM wallets WHERE id = $1`, walletId)
+	s.Require().NoError(err)
+
+	err = s.psql.Database().GetContext(context.Background(), &ledger,
+		`SELECT COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0)
+		FROM ledger_entries
+		WHERE wallet_id = $1`, walletId)
+	s.Require().NoError(err)
+
+	return stored, ledger
+}
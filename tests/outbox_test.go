@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"wallet-service/internal/domain"
+)
+
+func (s *IntegrationTestSuite) TestWalletEventsOutbox() {
+	_, err := s.usersRepo.UpsertUser(context.Background(), existingUser, time.Now())
+	s.Require().NoError(err)
+
+	wallet := domain.WalletInfo{
+		Name:     "wallet 1",
+		Balance:  domain.NewMoney(10000, "USD"),
+		Currency: "USD",
+	}
+
+	var createdWallet struct {
+		Wallet domain.Wallet `json:"wallet"`
+	}
+
+	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, &createdWallet, existingUser)
+
+	fullWalletPath := walletPath + "/" + createdWallet.Wallet.Id.String()
+
+	name := "wallet 2"
+	update := domain.WalletPatch{Name: &name}
+
+	anyVersion := http.Header{"If-Match": []string{"*"}}
+
+	s.sendHTTPRequestWithHeaders(http.MethodPatch, fullWalletPath, http.StatusOK, &update, nil, existingUser, anyVersion)
+	s.sendHTTPRequestWithHeaders(http.MethodDelete, fullWalletPath, http.StatusNoContent, nil, nil, existingUser, anyVersion)
+
+	var eventTypes []domain.WalletEventType
+
+	err = s.psql.Database().SelectContext(context.Background(), &eventTypes,
+		`SELECT event_type FROM outbox WHERE aggregate_id = $1 ORDER BY id`, createdWallet.Wallet.Id)
+	s.Require().NoError(err)
+
+	s.Require().Equal([]domain.WalletEventType{
+		domain.WalletCreated,
+		domain.WalletBalanceChanged,
+		domain.WalletUpdated,
+		domain.WalletDeleted,
+	}, eventTypes)
+}
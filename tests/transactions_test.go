@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"wallet-service/internal/domain"
+)
+
+func (s *IntegrationTestSuite) TestGetTransactions() {
+	_, err := s.usersRepo.UpsertUser(context.Background(), existingUser, time.Now())
+	s.Require().NoError(err)
+
+	var created struct {
+		Wallet domain.Wallet `json:"wallet"`
+	}
+
+	info := domain.WalletInfo{Name: "history", Balance: domain.NewMoney(10000, "USD"), Currency: "USD"}
+
+	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &info, &created, existingUser)
+
+	fullWalletPath := walletPath + "/" + created.Wallet.Id.String()
+
+	deposit := domain.BalanceChange{Amount: domain.NewMoney(2500, "USD")}
+	s.sendHTTPRequest(http.MethodPost, fullWalletPath+"/deposit", http.StatusOK, &deposit, nil, existingUser)
+
+	withdrawal := domain.BalanceChange{Amount: domain.NewMoney(1000, "USD")}
+	s.sendHTTPRequest(http.MethodPost, fullWalletPath+"/withdraw", http.StatusOK, &withdrawal, nil, existingUser)
+
+	s.Run("paginate newest first", func() {
+		var firstPage domain.TransactionPage
+
+		s.sendHTTPRequest(http.MethodGet, fullWalletPath+"/transactions?limit=2", http.StatusOK, nil, &firstPage, existingUser)
+
+		s.Require().Len(firstPage.Transactions, 2)
+		s.Require().Equal(domain.WalletTransactionWithdrawal, firstPage.Transactions[0].Type)
+		s.Require().NotEmpty(firstPage.NextCursor)
+
+		var secondPage domain.TransactionPage
+
+		s.sendHTTPRequest(http.MethodGet, fullWalletPath+"/transactions?limit=2&cursor="+firstPage.NextCursor,
+			http.StatusOK, nil, &secondPage, existingUser)
+
+		s.Require().Len(secondPage.Transactions, 1)
+		s.Require().Empty(secondPage.NextCursor)
+	})
+
+	s.Run("filter by type", func() {
+		var page domain.TransactionPage
+
+		s.sendHTTPRequest(http.MethodGet, fullWalletPath+"/transactions?type=deposit", http.StatusOK, nil, &page, existingUser)
+
+		s.Require().Len(page.Transactions, 2)
+	})
+
+	s.Run("unknown type", func() {
+		s.sendHTTPRequest(http.MethodGet, fullWalletPath+"/transactions?type=refund", http.StatusBadRequest, nil, nil, existingUser)
+	})
+
+	s.Run("invalid limit", func() {
+		for _, limit := range []string{"0", "-1", "ten"} {
+			var body errorResponse
+
+			s.sendHTTPRequest(http.MethodGet, fullWalletPath+"/transactions?limit="+limit, http.StatusBadRequest, nil, &body,
+				existingUser)
+			s.Require().Equal("invalid_limit", body.Error.Code)
+		}
+	})
+
+	s.Run("limit above the maximum is capped", func() {
+		var page domain.TransactionPage
+
+		s.sendHTTPRequest(http.MethodGet, fullWalletPath+"/transactions?limit=1000", http.StatusOK, nil, &page, existingUser)
+
+		s.Require().Len(page.Transactions, 3)
+	})
+}
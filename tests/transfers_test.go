@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"wallet-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const transferPath = "/api/v1/transfers"
+
+func (s *IntegrationTestSuite) TestTransfer() {
+	_, err := s.usersRepo.UpsertUser(context.Background(), existingUser, time.Now())
+	s.Require().NoError(err)
+
+	createWallet := func(info domain.WalletInfo) domain.Wallet {
+		var created struct {
+			Wallet domain.Wallet `json:"wallet"`
+		}
+
+		s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &info, &created, existingUser)
+
+		return created.Wallet
+	}
+
+	from := createWallet(domain.WalletInfo{Name: "from", Balance: domain.NewMoney(10000, "USD"), Currency: "USD"})
+	to := createWallet(domain.WalletInfo{Name: "to", Balance: domain.NewMoney(1000, "USD"), Currency: "USD"})
+	rub := createWallet(domain.WalletInfo{Name: "rub", Balance: domain.NewMoney(1000, "RUB"), Currency: "RUB"})
+
+	s.Run("transfer successfully", func() {
+		var result struct {
+			Transfer domain.TransferResult `json:"transfer"`
+		}
+
+		transfer := domain.Transfer{FromWalletId: from.Id, ToWalletId: to.Id, Amount: domain.NewMoney(4000, "USD")}
+
+		s.sendHTTPRequest(http.MethodPost, transferPath, http.StatusOK, &transfer, &result, existingUser)
+
+		s.Require().Equal(domain.NewMoney(6000, "USD"), result.Transfer.From.Balance)
+		s.Require().Equal(domain.NewMoney(5000, "USD"), result.Transfer.To.Balance)
+	})
+
+	s.Run("insufficient funds", func() {
+		transfer := domain.Transfer{FromWalletId: from.Id, ToWalletId: to.Id, Amount: domain.NewMoney(100000, "USD")}
+
+		s.sendHTTPRequest(http.MethodPost, transferPath, http.StatusUnprocessableEntity, &transfer, nil, existingUser)
+	})
+
+	s.Run("currency mismatch", func() {
+		transfer := domain.Transfer{FromWalletId: from.Id, ToWalletId: rub.Id, Amount: domain.NewMoney(100, "USD")}
+
+		s.sendHTTPRequest(http.MethodPost, transferPath, http.StatusBadRequest, &transfer, nil, existingUser)
+	})
+
+	s.Run("same wallet", func() {
+		transfer := domain.Transfer{FromWalletId: from.Id, ToWalletId: from.Id, Amount: domain.NewMoney(100, "USD")}
+
+		s.sendHTTPRequest(http.MethodPost, transferPath, http.StatusBadRequest, &transfer, nil, existingUser)
+	})
+
+	s.Run("destination wallet not found", func() {
+		transfer := domain.Transfer{FromWalletId: from.Id, ToWalletId: uuid.New(), Amount: domain.NewMoney(100, "USD")}
+
+		s.sendHTTPRequest(http.MethodPost, transferPath, http.StatusNotFound, &transfer, nil, existingUser)
+	})
+
+	s.Run("destination wallet of another user", func() {
+		otherUser := domain.User{Id: uuid.New()}
+
+		_, err := s.usersRepo.UpsertUser(context.Background(), otherUser, time.Now())
+		s.Require().NoError(err)
+
+		var other struct {
+			Wallet domain.Wallet `json:"wallet"`
+		}
+
+		info := domain.WalletInfo{Name: "other", Balance: domain.NewMoney(1000, "USD"), Currency: "USD"}
+		s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &info, &other, otherUser)
+
+		var missing, foreign errorResponse
+
+		transfer := domain.Transfer{FromWalletId: from.Id, ToWalletId: uuid.New(), Amount: domain.NewMoney(100, "USD")}
+		s.sendHTTPRequest(http.MethodPost, transferPath, http.StatusNotFound, &transfer, &missing, existingUser)
+
+		transfer.ToWalletId = other.Wallet.Id
+		s.sendHTTPRequest(http.MethodPost, transferPath, http.StatusNotFound, &transfer, &foreign, existingUser)
+
+		s.Require().Equal(missing.Error.Code, foreign.Error.Code, "a foreign wallet looks like a missing one")
+
+		var stored struct {
+			Wallet domain.Wallet `json:"wallet"`
+		}
+
+		s.sendHTTPRequest(http.MethodGet, walletPath+"/"+other.Wallet.Id.String(), http.StatusOK, nil, &stored, otherUser)
+		s.Require().Equal(domain.NewMoney(1000, "USD"), stored.Wallet.Balance)
+	})
+}
@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"wallet-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func (s *IntegrationTestSuite) TestUserState() {
+	user := domain.User{
+		Id: uuid.New(),
+	}
+
+	_, err := s.usersRepo.UpsertUser(context.Background(), user, time.Now())
+	s.Require().NoError(err)
+
+	wallet := domain.WalletInfo{
+		Name:     "wallet 1",
+		Balance:  domain.NewMoney(10000, "USD"),
+		Currency: "USD",
+	}
+
+	var createdWallet struct {
+		Wallet domain.Wallet `json:"wallet"`
+	}
+
+	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, &createdWallet, user)
+
+	fullWalletPath := walletPath + "/" + createdWallet.Wallet.Id.String()
+
+	s.Run("blocked user can read but not change wallets", func() {
+		blockedAt := time.Now()
+		user.BlockedAt = &blockedAt
+
+		_, err := s.usersRepo.UpsertUser(context.Background(), user, time.Now())
+		s.Require().NoError(err)
+
+		var body errorResponse
+
+		deposit := domain.BalanceChange{Amount: domain.NewMoney(5000, "USD")}
+
+		s.sendHTTPRequest(http.MethodPost, fullWalletPath+"/deposit", http.StatusForbidden, &deposit, &body, user)
+		s.Require().Equal("user_blocked", body.Error.Code)
+
+		s.sendHTTPRequest(http.MethodGet, fullWalletPath, http.StatusOK, nil, nil, user)
+	})
+
+	s.Run("deleted user sees nothing", func() {
+		deletedAt := time.Now()
+		user.DeletedAt = &deletedAt
+
+		_, err := s.usersRepo.UpsertUser(context.Background(), user, time.Now())
+		s.Require().NoError(err)
+
+		var body errorResponse
+
+		s.sendHTTPRequest(http.MethodGet, fullWalletPath, http.StatusNotFound, nil, &body, user)
+		s.Require().Equal("user_deleted", body.Error.Code)
+	})
+
+	s.Run("deleted user wallets are frozen", func() {
+		frozen, err := s.walletsRepo.FreezeUserWallets(context.Background(), user.Id)
+		s.Require().NoError(err)
+		s.Require().Equal(int64(1), frozen)
+
+		frozenWallet, err := s.walletsRepo.GetWallet(context.Background(), createdWallet.Wallet.Id, user.Id.String())
+		s.Require().NoError(err)
+		s.Require().Equal(domain.WalletFrozen, frozenWallet.Status)
+		s.Require().NotNil(frozenWallet.ClosureRequestedAt)
+	})
+}
+
+func (s *IntegrationTestSuite) TestUpsertUserIgnoresStaleEvents() {
+	user := domain.User{
+		Id: uuid.New(),
+	}
+
+	blockedAt := time.Now()
+	blockedUser := user
+	blockedUser.BlockedAt = &blockedAt
+
+	applied, err := s.usersRepo.UpsertUser(context.Background(), blockedUser, blockedAt)
+	s.Require().NoError(err)
+	s.Require().True(applied)
+
+	s.Run("older event is skipped", func() {
+		applied, err := s.usersRepo.UpsertUser(context.Background(), user, blockedAt.Add(-time.Minute))
+		s.Require().NoError(err)
+		s.Require().False(applied)
+
+		stored, err := s.usersRepo.GetUser(context.Background(), user.Id)
+		s.Require().NoError(err)
+		s.Require().NotNil(stored.BlockedAt)
+	})
+
+	s.Run("redelivered event is skipped", func() {
+		applied, err := s.usersRepo.UpsertUser(context.Background(), blockedUser, blockedAt)
+		s.Require().NoError(err)
+		s.Require().False(applied)
+	})
+
+	s.Run("newer event is applied", func() {
+		applied, err := s.usersRepo.UpsertUser(context.Background(), user, blockedAt.Add(time.Minute))
+		s.Require().NoError(err)
+		s.Require().True(applied)
+
+		stored, err := s.usersRepo.GetUser(context.Background(), user.Id)
+		s.Require().NoError(err)
+		s.Require().Nil(stored.BlockedAt)
+	})
+}
+
+func (s *IntegrationTestSuite) TestUpsertUsers() {
+	staleUser := domain.User{
+		Id: uuid.New(),
+	}
+
+	now := time.Now()
+
+	_, err := s.usersRepo.UpsertUser(context.Background(), staleUser, now)
+	s.Require().NoError(err)
+
+	newUser := domain.User{
+		Id:        uuid.New(),
+		BlockedAt: &now,
+	}
+
+	applied, err := s.usersRepo.UpsertUsers(context.Background(), []domain.UserChange{
+		{User: newUser, EventAt: now},
+		{User: domain.User{Id: staleUser.Id, BlockedAt: &now}, EventAt: now.Add(-time.Minute)},
+	})
+	s.Require().NoError(err)
+	s.Require().Equal([]uuid.UUID{newUser.Id}, applied)
+
+	stored, err := s.usersRepo.GetUser(context.Background(), newUser.Id)
+	s.Require().NoError(err)
+	s.Require().NotNil(stored.BlockedAt)
+
+	stored, err = s.usersRepo.GetUser(context.Background(), staleUser.Id)
+	s.Require().NoError(err)
+	s.Require().Nil(stored.BlockedAt)
+}
@@ -2,7 +2,11 @@ package tests
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 	"wallet-service/internal/domain"
 
 	"github.com/google/uuid"
@@ -15,9 +19,7 @@ var existingUser = domain.User{
 }
 
 func (s *IntegrationTestSuite) TestCreateWallet() {
-	wallet := domain.Wallet{
-		Id:       uuid.New(),
-		UserId:   uuid.NewString(),
+	wallet := domain.WalletInfo{
 		Name:     "wallet 1",
 		Currency: "USD",
 	}
@@ -27,52 +29,108 @@ func (s *IntegrationTestSuite) TestCreateWallet() {
 	})
 
 	s.Run("wallet successfully created", func() {
-		err := s.usersRepo.UpsertUser(context.Background(), existingUser)
+		_, err := s.usersRepo.UpsertUser(context.Background(), existingUser, time.Now())
 		s.Require().NoError(err)
 
-		wallet.Id = existingUser.Id
-
-		var createdWallet domain.Wallet
+		var createdWallet struct {
+			Wallet domain.Wallet `json:"wallet"`
+		}
 
 		s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, &createdWallet, existingUser)
 
-		s.Require().Equal(wallet.Id, createdWallet.Id)
-		s.Require().Equal(wallet.UserId, createdWallet.UserId)
-		s.Require().Equal(wallet.Name, createdWallet.Name)
-		s.Require().Equal(0.0, createdWallet.Balance)
-		s.Require().Equal(wallet.Currency, createdWallet.Currency)
+		s.Require().NotEqual(uuid.Nil, createdWallet.Wallet.Id)
+		s.Require().Equal(wallet.Name, createdWallet.Wallet.Name)
+		s.Require().Equal(int64(0), createdWallet.Wallet.Balance.Amount)
+		s.Require().Equal(wallet.Currency, createdWallet.Wallet.Currency)
 	})
 
 	s.Run("wallet doesn't belong to the user", func() {
-		err := s.usersRepo.UpsertUser(context.Background(), existingUser)
+		_, err := s.usersRepo.UpsertUser(context.Background(), existingUser, time.Now())
 		s.Require().NoError(err)
 
 		otherUser := domain.User{
 			Id: uuid.New(),
 		}
 
-		err = s.usersRepo.UpsertUser(context.Background(), otherUser)
+		_, err = s.usersRepo.UpsertUser(context.Background(), otherUser, time.Now())
 		s.Require().NoError(err)
 
-		existingUser.Id = otherUser.Id
+		var page domain.WalletPage
 
-		s.sendHTTPRequest(http.MethodGet, walletPath, http.StatusNotFound, &wallet, nil, existingUser)
+		s.sendHTTPRequest(http.MethodGet, walletPath, http.StatusOK, nil, &page, otherUser)
+
+		s.Require().Len(page.Wallets, 0)
+	})
+}
+
+func (s *IntegrationTestSuite) TestCreateWalletValidation() {
+	_, err := s.usersRepo.UpsertUser(context.Background(), existingUser, time.Now())
+	s.Require().NoError(err)
+
+	create := func(document string, statusCode int) errorResponse {
+		var body errorResponse
+
+		s.sendHTTPRequest(http.MethodPost, walletPath, statusCode, json.RawMessage(document), &body, existingUser)
+
+		return body
+	}
+
+	s.Run("every invalid field is reported", func() {
+		body := create(`{"name": " ", "currency": "BANANA"}`, http.StatusBadRequest)
+
+		s.Require().Equal("invalid_fields", body.Error.Code)
+		s.Require().Equal([]domain.FieldError{
+			{Field: "name", Message: "must not be empty"},
+			{Field: "currency", Message: "must be an ISO 4217 currency code"},
+		}, body.Error.Fields)
+	})
+
+	s.Run("name is limited in length", func() {
+		name := strings.Repeat("a", domain.MaxWalletNameLength+1)
+		body := create(`{"name": "`+name+`", "currency": "USD"}`, http.StatusBadRequest)
+
+		s.Require().Equal("name", body.Error.Fields[0].Field)
+	})
+
+	s.Run("balance without a currency is rejected", func() {
+		body := create(`{"name": "wallet", "currency": "USD", "balance": {"amount": "1.00"}}`, http.StatusBadRequest)
+
+		s.Require().Equal("unknown_currency", body.Error.Code)
+	})
+
+	s.Run("balance must not be negative", func() {
+		body := create(`{"name": "wallet", "currency": "USD", "balance": {"amount": "-1.00", "currency": "USD"}}`,
+			http.StatusBadRequest)
+
+		s.Require().Equal([]domain.FieldError{{Field: "balance", Message: "must not be negative"}}, body.Error.Fields)
+	})
+
+	s.Run("unknown fields are rejected", func() {
+		body := create(`{"name": "wallet", "currency": "USD", "owner": "me"}`, http.StatusBadRequest)
+
+		s.Require().Equal([]domain.FieldError{{Field: "owner", Message: "is not a known field"}}, body.Error.Fields)
+	})
+
+	s.Run("oversized body is rejected", func() {
+		body := create(`{"name": "`+strings.Repeat("a", 1<<16)+`", "currency": "USD"}`, http.StatusRequestEntityTooLarge)
+
+		s.Require().Equal("payload_too_large", body.Error.Code)
 	})
 }
 
 func (s *IntegrationTestSuite) TestGetWallet() {
-	wallet := domain.Wallet{
-		Id: uuid.New(),
-		UserId: existingUser.Id.String(),
+	wallet := domain.WalletInfo{
 		Name: "wallet 1",
-		Balance: 200.0,
+		Balance: domain.NewMoney(20000, "USD"),
 		Currency: "USD",
 	}
 
-	err := s.usersRepo.UpsertUser(context.Background(), existingUser)
+	_, err := s.usersRepo.UpsertUser(context.Background(), existingUser, time.Now())
 	s.Require().NoError(err)
 
-	var createdWallet domain.Wallet
+	var createdWallet struct {
+		Wallet domain.Wallet `json:"wallet"`
+	}
 
 	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, &createdWallet, existingUser)
 
@@ -81,16 +139,19 @@ func (s *IntegrationTestSuite) TestGetWallet() {
 	})
 
 	s.Run("get wallet successfully", func() {
-		walletId := uuid.UUID(createdWallet.Id).String()
+		walletId := createdWallet.Wallet.Id.String()
 		fullWalletPath := walletPath + "/" + walletId
 
-		s.sendHTTPRequest(http.MethodGet, fullWalletPath, http.StatusOK, nil, &createdWallet, existingUser)
+		var result struct {
+			Wallet domain.Wallet `json:"wallet"`
+		}
+
+		s.sendHTTPRequest(http.MethodGet, fullWalletPath, http.StatusOK, nil, &result, existingUser)
 
-		s.Require().Equal(wallet.Id, createdWallet.Id)
-		s.Require().Equal(wallet.UserId, createdWallet.UserId)
-		s.Require().Equal(wallet.Name, createdWallet.Name)
-		s.Require().Equal(wallet.Balance, createdWallet.Balance)
-		s.Require().Equal(wallet.Currency, createdWallet.Currency)
+		s.Require().Equal(createdWallet.Wallet.Id, result.Wallet.Id)
+		s.Require().Equal(wallet.Name, result.Wallet.Name)
+		s.Require().Equal(wallet.Balance, result.Wallet.Balance)
+		s.Require().Equal(wallet.Currency, result.Wallet.Currency)
 	})
 
 	s.Run("wallet not found", func() {
@@ -105,10 +166,10 @@ func (s *IntegrationTestSuite) TestGetWallet() {
 			Id: uuid.New(),
 		}
 
-		err := s.usersRepo.UpsertUser(context.Background(), otherUser)
+		_, err := s.usersRepo.UpsertUser(context.Background(), otherUser, time.Now())
 		s.Require().NoError(err)
 
-		walletId := uuid.UUID(createdWallet.Id).String()
+		walletId := createdWallet.Wallet.Id.String()
 		fullWalletPath := walletPath + "/" + walletId
 
 		s.sendHTTPRequest(http.MethodGet, fullWalletPath, http.StatusNotFound, nil, nil, otherUser)
@@ -116,18 +177,18 @@ func (s *IntegrationTestSuite) TestGetWallet() {
 }
 
 func (s *IntegrationTestSuite) TestUpdateWallet() {
-	wallet := domain.Wallet{
-		Id: uuid.New(),
-		UserId: existingUser.Id.String(),
+	wallet := domain.WalletInfo{
 		Name: "wallet 1",
-		Balance: 250.0,
+		Balance: domain.NewMoney(25000, "USD"),
 		Currency: "USD",
 	}
 
-	err := s.usersRepo.UpsertUser(context.Background(), existingUser)
+	_, err := s.usersRepo.UpsertUser(context.Background(), existingUser, time.Now())
 	s.Require().NoError(err)
 
-	var createdWallet domain.Wallet
+	var createdWallet struct {
+		Wallet domain.Wallet `json:"wallet"`
+	}
 
 	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, &createdWallet, existingUser)
 
@@ -136,7 +197,8 @@ func (s *IntegrationTestSuite) TestUpdateWallet() {
 			Id: uuid.New(),
 		}
 
-		s.sendHTTPRequest(http.MethodPatch, walletPath, http.StatusNotFound, nil, nil, nonExistingUser)
+		s.sendHTTPRequestWithHeaders(http.MethodPatch, walletPath+"/"+createdWallet.Wallet.Id.String(),
+			http.StatusNotFound, &domain.WalletPatch{}, nil, nonExistingUser, http.Header{"If-Match": []string{"*"}})
 	})
 
 	s.Run("wallet not found", func() {
@@ -147,16 +209,23 @@ func (s *IntegrationTestSuite) TestUpdateWallet() {
 	})
 
 	s.Run("wallet updated successfully", func() {
-		updatedWallet := domain.Wallet{
-			Name: "Blue frog",
+		name := "Blue frog"
+		updatedWallet := domain.WalletPatch{
+			Name: &name,
 		}
 
-		walletId := uuid.UUID(createdWallet.Id).String()
+		walletId := createdWallet.Wallet.Id.String()
 		fullWalletPath := walletPath + "/" + walletId
 
-		s.sendHTTPRequest(http.MethodPatch, fullWalletPath, http.StatusOK, &updatedWallet, &createdWallet, existingUser)
+		var result struct {
+			Wallet domain.Wallet `json:"updated wallet"`
+		}
+
+		s.sendHTTPRequestWithHeaders(http.MethodPatch, fullWalletPath, http.StatusOK, &updatedWallet, &result,
+			existingUser, http.Header{"If-Match": []string{"*"}})
 
-		s.Require().Equal(updatedWallet.Name, createdWallet.Name)
+		s.Require().Equal(createdWallet.Wallet.Id, result.Wallet.Id)
+		s.Require().Equal(name, result.Wallet.Name)
 	})
 
 	s.Run("wallet doesn't belong to the user", func() {
@@ -164,29 +233,186 @@ func (s *IntegrationTestSuite) TestUpdateWallet() {
 			Id: uuid.New(),
 		}
 
-		err := s.usersRepo.UpsertUser(context.Background(), otherUser)
+		_, err := s.usersRepo.UpsertUser(context.Background(), otherUser, time.Now())
 		s.Require().NoError(err)
 
-		walletId := uuid.UUID(createdWallet.Id).String()
+		walletId := createdWallet.Wallet.Id.String()
 		fullWalletPath := walletPath + "/" + walletId
 
 		s.sendHTTPRequest(http.MethodGet, fullWalletPath, http.StatusNotFound, nil, nil, otherUser)
 	})
 }
 
-func (s *IntegrationTestSuite) TestDeleteWallet() {
-	wallet := domain.Wallet{
+func (s *IntegrationTestSuite) TestWalletIfMatch() {
+	_, err := s.usersRepo.UpsertUser(context.Background(), existingUser, time.Now())
+	s.Require().NoError(err)
+
+	wallet := domain.WalletInfo{
+		Name:     "wallet 1",
+		Balance:  domain.NewMoney(10000, "USD"),
+		Currency: "USD",
+	}
+
+	var createdWallet struct {
+		Wallet domain.Wallet `json:"wallet"`
+	}
+
+	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, &createdWallet, existingUser)
+
+	fullWalletPath := walletPath + "/" + createdWallet.Wallet.Id.String()
+
+	headers := s.sendHTTPRequestWithHeaders(http.MethodGet, fullWalletPath, http.StatusOK, nil, nil, existingUser, nil)
+	etag := headers.Get("ETag")
+	s.Require().NotEmpty(etag)
+
+	name := "wallet 2"
+	update := domain.WalletPatch{Name: &name}
+
+	s.Run("missing If-Match is rejected", func() {
+		var body errorResponse
+
+		s.sendHTTPRequest(http.MethodPatch, fullWalletPath, http.StatusPreconditionRequired, &update, &body, existingUser)
+		s.Require().Equal("precondition_required", body.Error.Code)
+
+		s.sendHTTPRequest(http.MethodDelete, fullWalletPath, http.StatusPreconditionRequired, nil, nil, existingUser)
+	})
+
+	s.Run("matching If-Match updates and returns the full wallet", func() {
+		var result struct {
+			Wallet domain.Wallet `json:"updated wallet"`
+		}
+
+		headers := s.sendHTTPRequestWithHeaders(http.MethodPatch, fullWalletPath, http.StatusOK, &update, &result,
+			existingUser, http.Header{"If-Match": []string{etag}})
+
+		s.Require().Equal(name, result.Wallet.Name)
+		s.Require().Equal(createdWallet.Wallet.Id, result.Wallet.Id)
+		s.Require().Equal(int64(10000), result.Wallet.Balance.Amount)
+		s.Require().Equal(createdWallet.Wallet.Version+1, result.Wallet.Version)
+		s.Require().NotEqual(etag, headers.Get("ETag"))
+	})
+
+	s.Run("stale If-Match is rejected", func() {
+		var body errorResponse
+
+		s.sendHTTPRequestWithHeaders(http.MethodPatch, fullWalletPath, http.StatusPreconditionFailed, &update, &body,
+			existingUser, http.Header{"If-Match": []string{etag}})
+		s.Require().Equal("version_mismatch", body.Error.Code)
+
+		s.sendHTTPRequestWithHeaders(http.MethodDelete, fullWalletPath, http.StatusPreconditionFailed, nil, nil,
+			existingUser, http.Header{"If-Match": []string{etag}})
+	})
+
+	s.Run("malformed If-Match is rejected", func() {
+		s.sendHTTPRequestWithHeaders(http.MethodPatch, fullWalletPath, http.StatusBadRequest, &update, nil,
+			existingUser, http.Header{"If-Match": []string{`W/"1"`}})
+	})
+}
+
+func (s *IntegrationTestSuite) TestWalletMergePatch() {
+	user := domain.User{
 		Id: uuid.New(),
-		UserId: existingUser.Id.String(),
+	}
+
+	_, err := s.usersRepo.UpsertUser(context.Background(), user, time.Now())
+	s.Require().NoError(err)
+
+	var first, second struct {
+		Wallet domain.Wallet `json:"wallet"`
+	}
+
+	wallet := domain.WalletInfo{
+		Name:     "wallet 1",
+		Balance:  domain.NewMoney(10000, "USD"),
+		Currency: "USD",
+	}
+
+	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, &first, user)
+	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, &second, user)
+
+	anyVersion := http.Header{
+		"If-Match":     []string{"*"},
+		"Content-Type": []string{"application/merge-patch+json"},
+	}
+
+	patch := func(walletId uuid.UUID, document string, statusCode int, result any) {
+		s.sendHTTPRequestWithHeaders(http.MethodPatch, walletPath+"/"+walletId.String(), statusCode,
+			json.RawMessage(document), result, user, anyVersion)
+	}
+
+	type patched struct {
+		Wallet domain.Wallet `json:"updated wallet"`
+	}
+
+	s.Run("patches only the given fields", func() {
+		var result patched
+
+		patch(first.Wallet.Id, `{"description": "groceries", "tags": ["home", "food"]}`, http.StatusOK, &result)
+
+		s.Require().Equal("wallet 1", result.Wallet.Name)
+		s.Require().Equal("groceries", result.Wallet.Description)
+		s.Require().Equal([]string{"home", "food"}, result.Wallet.Tags)
+	})
+
+	s.Run("null resets a field", func() {
+		var result patched
+
+		patch(first.Wallet.Id, `{"description": null, "tags": null}`, http.StatusOK, &result)
+
+		s.Require().Empty(result.Wallet.Description)
+		s.Require().Empty(result.Wallet.Tags)
+	})
+
+	s.Run("only one wallet is the default one", func() {
+		var result patched
+
+		patch(first.Wallet.Id, `{"isDefault": true}`, http.StatusOK, &result)
+		s.Require().True(result.Wallet.IsDefault)
+
+		patch(second.Wallet.Id, `{"isDefault": true}`, http.StatusOK, &result)
+		s.Require().True(result.Wallet.IsDefault)
+
+		stored, err := s.walletsRepo.GetWallet(context.Background(), first.Wallet.Id, user.Id.String())
+		s.Require().NoError(err)
+		s.Require().False(stored.IsDefault)
+	})
+
+	s.Run("every invalid field is reported", func() {
+		var body errorResponse
+
+		patch(first.Wallet.Id, `{"name": "", "currency": "EUR", "balance": "1.00", "color": "red"}`,
+			http.StatusBadRequest, &body)
+
+		s.Require().Equal("invalid_fields", body.Error.Code)
+		s.Require().Equal([]domain.FieldError{
+			{Field: "balance", Message: "is immutable"},
+			{Field: "color", Message: "is not a wallet field"},
+			{Field: "currency", Message: "is immutable"},
+			{Field: "name", Message: "must not be empty"},
+		}, body.Error.Fields)
+	})
+
+	s.Run("patch must be an object", func() {
+		var body errorResponse
+
+		patch(first.Wallet.Id, `["name"]`, http.StatusBadRequest, &body)
+		s.Require().Equal("invalid_patch", body.Error.Code)
+	})
+}
+
+func (s *IntegrationTestSuite) TestDeleteWallet() {
+	wallet := domain.WalletInfo{
 		Name: "wallet 1",
-		Balance: 300.0,
+		Balance: domain.NewMoney(30000, "USD"),
 		Currency: "USD",
 	}
 
-	err := s.usersRepo.UpsertUser(context.Background(), existingUser)
+	_, err := s.usersRepo.UpsertUser(context.Background(), existingUser, time.Now())
 	s.Require().NoError(err)
 
-	var createdWallet domain.Wallet
+	var createdWallet struct {
+		Wallet domain.Wallet `json:"wallet"`
+	}
 
 	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, &createdWallet, existingUser)
 
@@ -206,10 +432,11 @@ func (s *IntegrationTestSuite) TestDeleteWallet() {
 	})
 
 	s.Run("wallet successfully deleted", func() {
-		walletId := uuid.UUID(createdWallet.Id).String()
+		walletId := createdWallet.Wallet.Id.String()
 		fullWalletPath := walletPath + "/" + walletId
 
-		s.sendHTTPRequest(http.MethodDelete, fullWalletPath, http.StatusNoContent, nil, nil, existingUser)
+		s.sendHTTPRequestWithHeaders(http.MethodDelete, fullWalletPath, http.StatusNoContent, nil, nil,
+			existingUser, http.Header{"If-Match": []string{"*"}})
 	})
 
 	s.Run("wallet doesn't belong to the user", func() {
@@ -217,10 +444,10 @@ func (s *IntegrationTestSuite) TestDeleteWallet() {
 			Id: uuid.New(),
 		}
 
-		err := s.usersRepo.UpsertUser(context.Background(), otherUser)
+		_, err := s.usersRepo.UpsertUser(context.Background(), otherUser, time.Now())
 		s.Require().NoError(err)
 
-		walletId := uuid.UUID(createdWallet.Id).String()
+		walletId := createdWallet.Wallet.Id.String()
 		fullWalletPath := walletPath + "/" + walletId
 
 		s.sendHTTPRequest(http.MethodGet, fullWalletPath, http.StatusNotFound, nil, nil, otherUser)
@@ -228,78 +455,198 @@ func (s *IntegrationTestSuite) TestDeleteWallet() {
 }
 
 func (s *IntegrationTestSuite) TestGetWallets() {
-	err := s.usersRepo.UpsertUser(context.Background(), existingUser)
+	user := domain.User{
+		Id: uuid.New(),
+	}
+
+	_, err := s.usersRepo.UpsertUser(context.Background(), user, time.Now())
 	s.Require().NoError(err)
 
-	var arrWallets []domain.Wallet
+	wallets := []domain.WalletInfo{
+		{Name: "wallet 1", Balance: domain.NewMoney(10000, "USD"), Currency: "USD"},
+		{Name: "wallet 2", Balance: domain.NewMoney(5000, "RUB"), Currency: "RUB"},
+		{Name: "wallet 3", Balance: domain.NewMoney(100000, "USD"), Currency: "USD"},
+	}
 
-	wallet1 := domain.Wallet{
-		Id: uuid.New(),
-		UserId: existingUser.Id.String(),
-		Name: "wallet 1",
-		Balance: 100.0,
-		Currency: "USD",
+	for _, wallet := range wallets {
+		s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, user)
 	}
-	
-	wallet2 := domain.Wallet{
+
+	s.Run("get successfully all wallets", func() {
+		var page domain.WalletPage
+
+		s.sendHTTPRequest(http.MethodGet, walletPath, http.StatusOK, nil, &page, user)
+
+		s.Require().Len(page.Wallets, len(wallets))
+		s.Require().Empty(page.NextCursor)
+	})
+
+	s.Run("user doesn't own any wallets", func() {
+		otherUser := domain.User{
+			Id: uuid.New(),
+		}
+
+		_, err := s.usersRepo.UpsertUser(context.Background(), otherUser, time.Now())
+		s.Require().NoError(err)
+
+		var page domain.WalletPage
+
+		s.sendHTTPRequest(http.MethodGet, walletPath, http.StatusOK, nil, &page, otherUser)
+
+		s.Require().Len(page.Wallets, 0)
+	})
+}
+
+func (s *IntegrationTestSuite) TestGetWalletsPagination() {
+	user := domain.User{
 		Id: uuid.New(),
-		UserId: existingUser.Id.String(),
-		Name: "wallet 2",
-		Balance: 50.0,
-		Currency: "RUB",
 	}
-	
-	wallet3 := domain.Wallet{
-		Id: uuid.New(),
-		UserId: existingUser.Id.String(),
-		Name: "wallet 3",
-		Balance: 1000.0,
+
+	_, err := s.usersRepo.UpsertUser(context.Background(), user, time.Now())
+	s.Require().NoError(err)
+
+	for i, balance := range []int64{500, 100, 300, 100, 200} {
+		wallet := domain.WalletInfo{
+			Name:     fmt.Sprintf("savings %d", i),
+			Balance:  domain.NewMoney(balance, "USD"),
+			Currency: "USD",
+		}
+
+		s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, nil, user)
+	}
+
+	other := domain.WalletInfo{
+		Name:     "travel",
+		Balance:  domain.NewMoney(100, "EUR"),
+		Currency: "EUR",
+	}
+
+	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &other, nil, user)
+
+	s.Run("pages cover every wallet exactly once", func() {
+		var (
+			seen   []uuid.UUID
+			cursor string
+		)
+
+		for {
+			var page domain.WalletPage
+
+			path := walletPath + "?sort=balance&order=desc&limit=2&cursor=" + cursor
+			s.sendHTTPRequest(http.MethodGet, path, http.StatusOK, nil, &page, user)
+
+			for _, wallet := range page.Wallets {
+				s.Require().NotContains(seen, wallet.Id)
+				seen = append(seen, wallet.Id)
+			}
+
+			if page.NextCursor == "" {
+				break
+			}
+
+			cursor = page.NextCursor
+		}
+
+		s.Require().Len(seen, 6)
+	})
+
+	s.Run("filters by currency, name prefix and balance", func() {
+		var page domain.WalletPage
+
+		path := walletPath + "?currency=USD&namePrefix=savings&minBalance=1.00&maxBalance=3.00&sort=balance"
+		s.sendHTTPRequest(http.MethodGet, path, http.StatusOK, nil, &page, user)
+
+		s.Require().Len(page.Wallets, 4)
+		s.Require().Empty(page.NextCursor)
+
+		for i, wallet := range page.Wallets {
+			s.Require().Equal("USD", wallet.Currency)
+
+			if i > 0 {
+				s.Require().LessOrEqual(page.Wallets[i-1].Balance.Amount, wallet.Balance.Amount)
+			}
+		}
+	})
+
+	s.Run("balance bounds require currency", func() {
+		var body errorResponse
+
+		s.sendHTTPRequest(http.MethodGet, walletPath+"?minBalance=1.00", http.StatusBadRequest, nil, &body, user)
+	})
+
+	s.Run("cursor of another sort is rejected", func() {
+		var page domain.WalletPage
+
+		s.sendHTTPRequest(http.MethodGet, walletPath+"?sort=name&limit=1", http.StatusOK, nil, &page, user)
+		s.Require().NotEmpty(page.NextCursor)
+
+		var body errorResponse
+
+		s.sendHTTPRequest(http.MethodGet, walletPath+"?sort=balance&cursor="+page.NextCursor, http.StatusBadRequest, nil, &body, user)
+		s.Require().Equal("invalid_cursor", body.Error.Code)
+	})
+
+	s.Run("invalid limit", func() {
+		for _, limit := range []string{"0", "-1", "ten"} {
+			var body errorResponse
+
+			s.sendHTTPRequest(http.MethodGet, walletPath+"?limit="+limit, http.StatusBadRequest, nil, &body, user)
+			s.Require().Equal("invalid_limit", body.Error.Code)
+		}
+	})
+}
+
+func (s *IntegrationTestSuite) TestDepositWithdraw() {
+	wallet := domain.WalletInfo{
+		Name: "wallet 1",
+		Balance: domain.NewMoney(10000, "USD"),
 		Currency: "USD",
 	}
-	
-	arrWallets = append(arrWallets, wallet1, wallet2, wallet3)
 
-	_, err = s.psql.Database().ExecContext(context.Background(),
-		`INSERT INTO wallets (name) VALUES ($1) WHERE id = $2 AND user_id = $3`,
-	wallet1.Name, wallet1.Id, wallet1.UserId)
+	_, err := s.usersRepo.UpsertUser(context.Background(), existingUser, time.Now())
 	s.Require().NoError(err)
 
-	_, err = s.psql.Database().ExecContext(context.Background(),
-		`INSERT INTO wallets (name) VALUES ($1) WHERE id = $2 AND user_id = $3`,
-	wallet2.Name, wallet2.Id, wallet2.UserId)
-	s.Require().NoError(err)
+	var createdWallet struct {
+		Wallet domain.Wallet `json:"wallet"`
+	}
 
-	_, err = s.psql.Database().ExecContext(context.Background(),
-		`INSERT INTO wallets (name) VALUES ($1) WHERE id = $2 AND user_id = $3`,
-	wallet3.Name, wallet3.Id, wallet3.UserId)
-	s.Require().NoError(err)
+	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet, &createdWallet, existingUser)
 
-	var created1, created2, created3 domain.Wallet
+	fullWalletPath := walletPath + "/" + createdWallet.Wallet.Id.String()
 
-	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet1, &created1, existingUser)
-	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet2, &created2, existingUser)
-	s.sendHTTPRequest(http.MethodPost, walletPath, http.StatusCreated, &wallet3, &created3, existingUser)
+	s.Run("deposit successfully", func() {
+		var result struct {
+			Wallet domain.Wallet `json:"wallet"`
+		}
 
-	s.Run("get successfully all wallets", func() {
-		var wallets []domain.Wallet
+		deposit := domain.BalanceChange{Amount: domain.NewMoney(5000, "USD")}
 
-		s.sendHTTPRequest(http.MethodGet, walletPath, http.StatusOK, nil, &wallets, existingUser)
+		s.sendHTTPRequest(http.MethodPost, fullWalletPath+"/deposit", http.StatusOK, &deposit, &result, existingUser)
 
-		s.Require().Len(wallets, len(arrWallets))
+		s.Require().Equal(domain.NewMoney(15000, "USD"), result.Wallet.Balance)
 	})
 
-	s.Run("user doesn't own any wallets", func() {
-		otherUser := domain.User{
-			Id: uuid.New(),
+	s.Run("withdraw successfully", func() {
+		var result struct {
+			Wallet domain.Wallet `json:"wallet"`
 		}
 
-		err := s.usersRepo.UpsertUser(context.Background(), otherUser)
-		s.Require().NoError(err)
+		withdrawal := domain.BalanceChange{Amount: domain.NewMoney(3000, "USD")}
 
-		var wallets []domain.Wallet
+		s.sendHTTPRequest(http.MethodPost, fullWalletPath+"/withdraw", http.StatusOK, &withdrawal, &result, existingUser)
 
-		s.sendHTTPRequest(http.MethodGet, walletPath, http.StatusNotFound, nil, &wallets, otherUser)
+		s.Require().Equal(domain.NewMoney(12000, "USD"), result.Wallet.Balance)
+	})
 
-		s.Require().Len(wallets, 0)
+	s.Run("overdraft rejected", func() {
+		withdrawal := domain.BalanceChange{Amount: domain.NewMoney(100000, "USD")}
+
+		s.sendHTTPRequest(http.MethodPost, fullWalletPath+"/withdraw", http.StatusUnprocessableEntity, &withdrawal, nil, existingUser)
 	})
-}
\ No newline at end of file
+
+	s.Run("non-positive amount rejected", func() {
+		deposit := domain.BalanceChange{Amount: domain.NewMoney(-1000, "USD")}
+
+		s.sendHTTPRequest(http.MethodPost, fullWalletPath+"/deposit", http.StatusBadRequest, &deposit, nil, existingUser)
+	})
+}